@@ -1,3 +1,4 @@
+// @permissions allow-run=git
 function main() {
     // Example:
     // quickgo exec git m="QuickGo update" origin=master tag
@@ -7,7 +8,7 @@ function main() {
     // Make git aware of all changes.
     let errD = os.exec("git add .");
     if (errD.error) {
-        return Fail(`Could not add files to git! ${errD.stdout}`);
+        return Fail(`Could not add files to git! ${errD.stderr || errD.stdout}`);
     };
 
     // Get the latest tag, increment the patch version and set it as the new tag.
@@ -38,7 +39,7 @@ function main() {
         errD = os.exec(`git commit -m "QuickGo update"`);
     }
     if (errD.error) {
-        return Fail(`Could not commit changes to git! ${errD.stdout}`);
+        return Fail(`Could not commit changes to git! ${errD.stderr || errD.stdout}`);
     }
 
     // Tag the commit with the tag provided by the user or the latest tag.
@@ -47,7 +48,7 @@ function main() {
         errD = os.exec(`git tag ${quickgo.environ.tag}`);
     }
     if (errD.error) {
-        return Fail(`Could not tag commit with tag ${quickgo.environ.tag}! ${errD.stdout}`);
+        return Fail(`Could not tag commit with tag ${quickgo.environ.tag}! ${errD.stderr || errD.stdout}`);
     }
 
     // Push changes to the remote repository.
@@ -64,7 +65,7 @@ function main() {
         let d = os.exec("git symbolic-ref refs/remotes/origin/HEAD --short");
         if (d.error) {
             // Log a warning, let git handle the default origin
-            console.warn(`Could not determine default remote repository, trying fallback method ${d.stdout}`);
+            console.warn(`Could not determine default remote repository, trying fallback method ${d.stderr}`);
         } else {
             // Trim the origin
             let remote = d.stdout.trim();
@@ -81,7 +82,7 @@ function main() {
     console.info(`Executing git command: ${pushStr}`)
     errD = os.exec(pushStr);
     if (errD.error) {
-        return Fail(`Could not push changes to remote repository! ${errD.stdout}`);
+        return Fail(`Could not push changes to remote repository! ${errD.stderr || errD.stdout}`);
     }
 
     return Success(`QuickGo git command executed successfully!`);
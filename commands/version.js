@@ -1,3 +1,4 @@
+// @permissions allow-read=. allow-write=.
 function main() {
     // Example:
     // quickgo exec version v=1.0.0
@@ -4,16 +4,19 @@ go 1.22.2
 
 require (
 	github.com/Nigel2392/goldcrest v1.0.4
+	github.com/alecthomas/chroma/v2 v2.14.0
 	github.com/dop251/goja v0.0.0-20240220182346-e401ed450204
 	github.com/elliotchance/orderedmap/v2 v2.2.0
 	github.com/kballard/go-shellquote v0.0.0-20180428030007-95032a82bc51
 	github.com/pkg/errors v0.9.1
+	github.com/yuin/goldmark v1.7.4
+	golang.org/x/crypto v0.31.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	github.com/dlclark/regexp2 v1.7.0 // indirect
+	github.com/dlclark/regexp2 v1.11.0 // indirect
 	github.com/go-sourcemap/sourcemap v2.1.3+incompatible // indirect
 	github.com/google/pprof v0.0.0-20230207041349-798e818bf904 // indirect
-	golang.org/x/text v0.3.8 // indirect
+	golang.org/x/text v0.21.0 // indirect
 )
@@ -1,21 +1,28 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"maps"
-	"net/http"
 	"os"
+	"os/signal"
+	"path"
 	"path/filepath"
 	"slices"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	quickgo "github.com/Nigel2392/quickgo/v2/quickgo"
 	"github.com/Nigel2392/quickgo/v2/quickgo/config"
 	"github.com/Nigel2392/quickgo/v2/quickgo/js"
 	"github.com/Nigel2392/quickgo/v2/quickgo/logger"
+	"github.com/Nigel2392/quickgo/v2/quickgo/registry"
+	"github.com/Nigel2392/quickgo/v2/quickgo/search"
 )
 
 type Flagger struct {
@@ -40,9 +47,21 @@ type Flagger struct {
 	// List the projects available for use
 	ListProjects bool
 
+	// Only list the projects with this tag.
+	Tag string
+
+	// Show detailed information about a saved project.
+	Info string
+
+	// The output format of listing and informational commands: table, json or yaml.
+	Output string
+
 	// List the commands available for all projects
 	ListCommands bool
 
+	// Search the file names and contents of all saved projects.
+	Search string
+
 	// Save a global command for this user.
 	SaveCommand string
 
@@ -52,10 +71,22 @@ type Flagger struct {
 	// Serve the project over HTTP
 	Serve bool
 
+	// Manage remote quickgo servers (add, remove, list).
+	Remote string
+
+	// Publish a saved project to the remote server.
+	Publish string
+
+	// The token to authenticate with when publishing to a remote server.
+	Token string
+
 	// Lock the project configuration.
 	// 1: Lock the project configuration.
 	// 0: Unlock the project configuration.
 	Lock int
+
+	// Allow JS commands to do everything, regardless of their permissions.
+	AllowAll bool
 }
 
 func (f *Flagger) CopyProject(proj *config.Project) {
@@ -122,9 +153,13 @@ func main() {
 		WrapPrefix: quickgo.ColoredLogWrapper,
 	})
 
+	// Logs are moved to stderr for machine-readable output,
+	// stdout should then only contain the output itself.
+	var logOutput = &switchWriter{Writer: os.Stdout}
+	var logfile = quickgo.Logfile(logOutput)
 	logger.SetOutput(
 		logger.OutputAll,
-		quickgo.Logfile(os.Stdout),
+		logfile,
 	)
 
 	flagSet.StringVar(&flagger.Project.Name, "name", "", "The name of the project.")
@@ -142,112 +177,166 @@ func main() {
 	flagSet.StringVar(&flagger.Use, "use", "", "Use the specified project configuration.")
 	flagSet.BoolVar(&flagger.Example, "example", false, "Print an example project configuration.")
 	flagSet.BoolVar(&flagger.ListProjects, "list", false, "List the projects available for use.")
+	flagSet.StringVar(&flagger.Tag, "tag", "", "Only list the projects with this tag, used with -list.")
+	flagSet.StringVar(&flagger.Info, "info", "", "Show detailed information about a saved project.")
+	flagSet.StringVar(&flagger.Output, "o", quickgo.OutputTable, "The output format of listing and informational commands: table, json or yaml.")
 	flagSet.BoolVar(&flagger.ListCommands, "list-commands", false, "List the commands available for all projects.")
+	flagSet.StringVar(&flagger.Search, "search", "", "Search the file names and contents of all saved projects.")
 	flagSet.StringVar(&flagger.SaveCommand, "save-command", "", "Save a global command for this user by providing a path to a JS file.")
 	flagSet.BoolVar(&flagger.Serve, "serve", false, "Serve the project over HTTP.")
+	flagSet.StringVar(&flagger.Remote, "remote", "", "Manage remote servers: 'add <name> <url>', 'remove <name>' or 'list [name]'.")
+	flagSet.StringVar(&flagger.Publish, "publish", "", "Publish a saved project to the remote provided with -remote.")
+	flagSet.StringVar(&flagger.Token, "token", "", "The token to authenticate with, stored for the remote when used with '-remote add'.")
 	flagSet.IntVar(&flagger.Lock, "lock", -1, "Lock the project configuration. 1=Lock, 0=Unlock.")
+	flagSet.BoolVar(&flagger.AllowAll, "allow-all", false, "Allow JS commands to do everything, use only for trusted commands.")
 	flagSet.BoolFunc("v", "Enable verbose logging.", enableVerboseLogging)
 
-	flagSet.Usage = func() {
-		fmt.Println(quickgo.Craft(quickgo.CMD_Cyan, "QuickGo: A simple project generator and server."))
-		fmt.Println("Usage: quickgo [-flags | exec <command> | <project-command>] [?args]")
-		fmt.Println("Available application flags:")
-		flagSet.VisitAll(func(f *flag.Flag) {
-
-			var name = f.Name
-			if f.DefValue != "" {
-				name = fmt.Sprintf("%s=%s", name, f.DefValue)
-			}
+	// The app is loaded after parsing the flags, so its logs follow the output format.
+	// Usage can be called while parsing, then it loads the app and prints the logo itself.
+	var parsed bool
+	var loadApp = func() {
+		if qg != nil {
+			return
+		}
+		if qg, err = quickgo.LoadApp(); err != nil {
+			logger.Fatal(1, err)
+		}
+	}
 
-			fmt.Printf(
-				"  -%s: %s\n",
-				quickgo.BuildColorString(
-					quickgo.CMD_Cyan,
-					quickgo.CMD_Bold,
-					name,
-				),
-				f.Usage,
-			)
-		})
+	flagSet.Usage = func() {
+		if quickgo.IsMachineOutput(flagger.Output) {
+			logOutput.Writer = os.Stderr
+		}
+		loadApp()
 
 		var commands, err = qg.ListJSFiles()
 		if err != nil {
 			logger.Warn(1, fmt.Errorf("failed to list commands: %w", err))
 		}
 
-		if len(commands) > 0 {
-			fmt.Println(
-				quickgo.Craft(quickgo.CMD_Blue, "Available commands:"),
-			)
-			for _, cmd := range commands {
-				fmt.Printf("  - %s\n", quickgo.Craft(
-					quickgo.CMD_Cyan, cmd,
-				))
-			}
-		}
-
 		// Try to load the project configuration.
 		// It might contain some more commands! :D
 		if qg.ProjectConfig == nil {
 			err = qg.LoadCurrentProject(".")
 		}
-		if err != nil && errors.Is(err, config.ErrProjectMissing) {
-			// No project found in the current directory.
-			fmt.Println(quickgo.Craft(quickgo.CMD_Red, "No project found in the current directory."))
-			fmt.Println("Run 'quickgo -example' to create an example project configuration.")
-		} else if err == nil {
 
-			// Project found, commands is map -> sort to slice.
-			var commands = make([]*config.ProjectCommand, 0, len(qg.ProjectConfig.Commands))
-			for _, v := range qg.ProjectConfig.Commands {
-				commands = append(commands, v)
+		var usage = &usageInfo{
+			Commands:        commandInfos(commands),
+			ProjectCommands: make([]*quickgo.CommandInfo, 0),
+			ProjectScripts:  make([]*quickgo.CommandInfo, 0),
+		}
+		if err == nil {
+			usage.ProjectCommands = quickgo.ProjectCommands(qg.ProjectConfig)
+			if scripts, err := qg.ProjectScripts(flagger.TargetDir); err == nil {
+				usage.ProjectScripts = scripts
+			} else {
+				logger.Warnf("failed to list project scripts: %s", err)
+			}
+		}
+
+		writeOutput(flagger.Output, usage, func() error {
+			if !parsed && quickgo.IsTerminal(os.Stdout) {
+				quickgo.PrintLogo()
 			}
 
-			slices.SortFunc(commands, func(a, b *config.ProjectCommand) int {
-				return strings.Compare(a.Name, b.Name)
+			fmt.Println(quickgo.Craft(quickgo.CMD_Cyan, "QuickGo: A simple project generator and server."))
+			fmt.Println("Usage: quickgo [-flags | exec <command> | <project-command>] [?args]")
+			fmt.Println("Available application flags:")
+			flagSet.VisitAll(func(f *flag.Flag) {
+
+				var name = f.Name
+				if f.DefValue != "" {
+					name = fmt.Sprintf("%s=%s", name, f.DefValue)
+				}
+
+				fmt.Printf(
+					"  -%s: %s\n",
+					quickgo.BuildColorString(
+						quickgo.CMD_Cyan,
+						quickgo.CMD_Bold,
+						name,
+					),
+					f.Usage,
+				)
 			})
 
-			if len(commands) == 0 {
+			if len(usage.Commands) > 0 {
+				fmt.Println(
+					quickgo.Craft(quickgo.CMD_Blue, "Available commands:"),
+				)
+				for _, cmd := range usage.Commands {
+					fmt.Printf("  - %s\n", quickgo.Craft(
+						quickgo.CMD_Cyan, cmd.Name,
+					))
+				}
+			}
+
+			if err != nil && errors.Is(err, config.ErrProjectMissing) {
+				// No project found in the current directory.
+				fmt.Println(quickgo.Craft(quickgo.CMD_Red, "No project found in the current directory."))
+				fmt.Println("Run 'quickgo -example' to create an example project configuration.")
+				return nil
+			} else if err != nil {
+				return nil
+			}
+
+			if len(usage.ProjectCommands) == 0 && len(usage.ProjectScripts) == 0 {
 				fmt.Println(quickgo.Craft(
 					quickgo.CMD_Yellow,
 					"No commands found in the project.",
 				))
-				return
+				return nil
 			}
 
-			fmt.Println(
-				quickgo.Craft(
-					quickgo.CMD_Blue,
-					"Available project commands:",
-				),
-			)
-			for _, c := range commands {
-				if c.Description == "" {
-					fmt.Printf("  - %s\n", quickgo.Craft(quickgo.CMD_Cyan, c.Name))
-					continue
-				}
-				fmt.Printf("  - %s: %s\n", quickgo.Craft(quickgo.CMD_Cyan, c.Name), c.Description)
+			if len(usage.ProjectCommands) > 0 {
+				fmt.Println(
+					quickgo.Craft(
+						quickgo.CMD_Blue,
+						"Available project commands:",
+					),
+				)
+				printCommands(usage.ProjectCommands)
 			}
-		}
-	}
 
-	quickgo.PrintLogo()
-
-	if len(os.Args) < 2 {
-		logger.Fatal(1, "no command provided, run 'quickgo -h' for more information.")
+			if len(usage.ProjectScripts) > 0 {
+				fmt.Println(
+					quickgo.Craft(
+						quickgo.CMD_Blue,
+						"Available project scripts:",
+					),
+				)
+				printCommands(usage.ProjectScripts)
+			}
+			return nil
+		})
 	}
 
-	// Initially load the application.
-	qg, err = quickgo.LoadApp()
+	err = flagSet.Parse(os.Args[1:])
 	if err != nil {
 		logger.Fatal(1, err)
 	}
+	parsed = true
 
-	err = flagSet.Parse(os.Args[1:])
-	if err != nil {
+	if err = quickgo.ValidateOutputFormat(flagger.Output); err != nil {
 		logger.Fatal(1, err)
 	}
 
+	// The logo is only useful to humans.
+	if quickgo.IsMachineOutput(flagger.Output) {
+		logOutput.Writer = os.Stderr
+	} else if quickgo.IsTerminal(os.Stdout) {
+		quickgo.PrintLogo()
+	}
+
+	if len(os.Args) < 2 {
+		logger.Fatal(1, "no command provided, run 'quickgo -h' for more information.")
+	}
+
+	// Initially load the application.
+	loadApp()
+
+	qg.AllowAll = flagger.AllowAll
+
 	switch {
 	case flagger.Save: // Save a project configuration from the current working / a specified directory.
 
@@ -302,9 +391,17 @@ func main() {
 
 		// Parse optional extra context provided by CLI arguments.
 		var (
-			ctx              = parseCommandlineContext(flagSet.Args(), false)
-			proj, close, err = qg.ReadProjectConfig(flagger.Use)
+			ctx   = parseCommandlineContext(flagSet.Args(), false)
+			proj  *config.Project
+			close func()
 		)
+
+		// Templates addressed as <remote>/<name> are fetched from a remote server.
+		if remote, name, ok := strings.Cut(flagger.Use, "/"); ok {
+			proj, close, err = qg.ReadRemoteProjectConfig(remote, name)
+		} else {
+			proj, close, err = qg.ReadProjectConfig(flagger.Use)
+		}
 		if err != nil {
 			logger.Fatal(1, fmt.Errorf("failed to read project config: %w", err))
 		}
@@ -366,16 +463,163 @@ func main() {
 
 	case flagger.ListProjects: // List all available (saved) projects.
 
-		var projects, err = qg.ListProjects()
+		var projects, err = qg.ListProjectObjects()
 		if err != nil {
 			logger.Fatal(1, fmt.Errorf("failed to list projects: %w", err))
 		}
 
-		fmt.Println(quickgo.Craft(quickgo.CMD_Red, "Projects:"))
+		projects = quickgo.FilterProjectsByTag(projects, flagger.Tag)
+
+		var summaries = make([]*quickgo.ProjectSummary, 0, len(projects))
 		for _, proj := range projects {
-			fmt.Printf("  - %s\n", quickgo.Craft(
-				quickgo.CMD_Blue, proj,
-			))
+			summaries = append(summaries, quickgo.NewProjectSummary(proj))
+		}
+
+		writeOutput(flagger.Output, summaries, func() error {
+			if len(summaries) == 0 && flagger.Tag != "" {
+				fmt.Printf("No projects tagged '%s'\n", flagger.Tag)
+				return nil
+			}
+
+			fmt.Println(quickgo.Craft(quickgo.CMD_Red, "Projects:"))
+			for _, proj := range summaries {
+				var name = quickgo.Craft(quickgo.CMD_Blue, proj.Name)
+				if proj.Description == "" {
+					fmt.Printf("  - %s\n", name)
+					continue
+				}
+				fmt.Printf("  - %s: %s\n", name, proj.Description)
+			}
+			return nil
+		})
+
+	case flagger.Info != "": // Show detailed information about a saved project.
+
+		var info, err = qg.ProjectInfo(flagger.Info)
+		if err != nil {
+			logger.Fatal(1, fmt.Errorf("failed to get project info: %w", err))
+		}
+
+		writeOutput(flagger.Output, info, func() error {
+			printProjectInfo(info)
+			return nil
+		})
+
+	case flagger.Search != "": // Search the file names and contents of all saved projects.
+
+		var results, err = qg.Search(flagger.Search, 0)
+		if err != nil {
+			logger.Fatal(1, fmt.Errorf("failed to search projects: %w", err))
+		}
+
+		if results == nil {
+			results = make([]*search.Result, 0)
+		}
+
+		writeOutput(flagger.Output, results, func() error {
+			if len(results) == 0 {
+				fmt.Printf("No results for '%s'\n", flagger.Search)
+				return nil
+			}
+
+			fmt.Println(quickgo.Craft(quickgo.CMD_Red, "Results:"))
+			for _, result := range results {
+				fmt.Printf("  - %s/%s\n", quickgo.Craft(
+					quickgo.CMD_Blue, result.Template,
+				), highlight(result.Path, result.PathMatches))
+
+				for _, line := range result.Lines {
+					fmt.Printf("      %s: %s\n", quickgo.Craft(
+						quickgo.CMD_Cyan, line.Number,
+					), highlight(line.Text, line.Matches))
+				}
+			}
+			return nil
+		})
+
+	case flagger.Publish != "": // Publish a saved project to a remote server.
+
+		if flagger.Remote == "" {
+			logger.Fatal(1, "no remote provided, usage: quickgo -publish <name> -remote <remote>")
+		}
+
+		var published, err = qg.PublishProject(flagger.Remote, flagger.Publish, flagger.Token)
+		if err != nil {
+			logger.Fatal(1, err)
+		}
+
+		logger.Infof("Published '%s' to remote '%s' (%d bytes)", published.Name, flagger.Remote, published.Size)
+
+	case flagger.Remote != "": // Manage the remote servers to fetch templates from.
+
+		var args = flagSet.Args()
+		switch strings.ToLower(flagger.Remote) {
+		case "add":
+			if len(args) != 2 {
+				logger.Fatal(1, "usage: quickgo -remote add <name> <url>")
+			}
+
+			if err = qg.AddRemote(args[0], args[1], flagger.Token); err != nil {
+				logger.Fatal(1, fmt.Errorf("failed to add remote: %w", err))
+			}
+
+		case "remove":
+			if len(args) != 1 {
+				logger.Fatal(1, "usage: quickgo -remote remove <name>")
+			}
+
+			if err = qg.RemoveRemote(args[0]); err != nil {
+				logger.Fatal(1, fmt.Errorf("failed to remove remote: %w", err))
+			}
+
+		case "list":
+			if len(args) == 0 {
+				var remotes = make([]*remoteInfo, 0, len(qg.Config.Remotes))
+				for _, name := range qg.ListRemotes() {
+					remotes = append(remotes, &remoteInfo{
+						Name: name,
+						URL:  qg.Config.Remotes[name].URL,
+					})
+				}
+
+				writeOutput(flagger.Output, remotes, func() error {
+					fmt.Println(quickgo.Craft(quickgo.CMD_Red, "Remotes:"))
+					for _, remote := range remotes {
+						fmt.Printf("  - %s: %s\n", quickgo.Craft(
+							quickgo.CMD_Blue, remote.Name,
+						), remote.URL)
+					}
+					return nil
+				})
+				return
+			}
+
+			var client, err = qg.RemoteClient(args[0])
+			if err != nil {
+				logger.Fatal(1, err)
+			}
+
+			templates, err := client.List()
+			if err != nil {
+				logger.Fatal(1, fmt.Errorf("failed to list templates on remote '%s': %w", args[0], err))
+			}
+
+			if templates == nil {
+				templates = make([]*registry.Template, 0)
+			}
+
+			writeOutput(flagger.Output, templates, func() error {
+				fmt.Println(quickgo.Craft(quickgo.CMD_Red, "Projects:"))
+				for _, tpl := range templates {
+					fmt.Printf("  - %s\n", quickgo.Craft(
+						quickgo.CMD_Blue, path.Join(args[0], tpl.Name),
+					))
+				}
+				return nil
+			})
+
+		default:
+			logger.Fatalf(1, "unknown remote action '%s', expected add, remove or list", flagger.Remote)
 		}
 
 	case flagger.Lock == 1 || flagger.Lock == 0: // Lock or unlock the project configuration.
@@ -408,29 +652,17 @@ func main() {
 			qg.Config,
 		)
 
-		var addr = fmt.Sprintf(
-			"%s:%s",
-			qg.Config.Host,
-			qg.Config.Port,
-		)
-		var server = &http.Server{
-			Addr:    addr,
-			Handler: qg.HttpHandler(),
-		}
+		// Shut the server down gracefully on SIGINT or SIGTERM.
+		var ctx, stop = signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
 
-		if qg.Config.TLSKey != "" && qg.Config.TLSCert != "" {
-			logger.Infof("Serving on https://%s", addr)
-			err = server.ListenAndServeTLS(
-				qg.Config.TLSCert,
-				qg.Config.TLSKey,
-			)
-		} else {
-			logger.Infof("Serving on http://%s", addr)
-			err = server.ListenAndServe()
+		if err = qg.ListenAndServe(ctx); err != nil {
+			logger.Fatal(1, fmt.Errorf("failed to start server: %w", err))
 		}
 
-		if err != nil {
-			logger.Fatal(1, fmt.Errorf("failed to start server: %w", err))
+		if err = logfile.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to close log file: %v\n", err)
+			os.Exit(1)
 		}
 
 	case flagger.ListCommands: // List all available (global) javascript commands.
@@ -440,17 +672,21 @@ func main() {
 			logger.Fatal(1, fmt.Errorf("failed to list commands: %w", err))
 		}
 
-		if len(commands) == 0 {
-			fmt.Println(quickgo.Craft(quickgo.CMD_Yellow, "No commands found."))
-			return
-		}
+		var infos = commandInfos(commands)
+		writeOutput(flagger.Output, infos, func() error {
+			if len(infos) == 0 {
+				fmt.Println(quickgo.Craft(quickgo.CMD_Yellow, "No commands found."))
+				return nil
+			}
 
-		fmt.Println(quickgo.Craft(quickgo.CMD_Red, "Commands:"))
-		for _, cmd := range commands {
-			fmt.Printf("  - %s\n", quickgo.Craft(
-				quickgo.CMD_Blue, cmd,
-			))
-		}
+			fmt.Println(quickgo.Craft(quickgo.CMD_Red, "Commands:"))
+			for _, cmd := range infos {
+				fmt.Printf("  - %s\n", quickgo.Craft(
+					quickgo.CMD_Blue, cmd.Name,
+				))
+			}
+			return nil
+		})
 
 	case flagger.SaveCommand != "": // Save a global command (js file with `main` function) for this user.
 
@@ -511,9 +747,16 @@ func main() {
 			logger.Fatal(1, "Cannot execute project commands outside of a project.")
 		}
 
+		// Commands in the project config take precedence over project scripts.
 		cmd, err = qg.ProjectConfig.Command(command, nil)
 		if err != nil && errors.Is(err, config.ErrCommandMissing) {
-			logger.Fatal(1, fmt.Errorf("command '%s' not found", command))
+			err = qg.ExecProjectScript(flagger.TargetDir, command, args[1:], ctx)
+			if err != nil && errors.Is(err, config.ErrCommandMissing) {
+				logger.Fatal(1, fmt.Errorf("command '%s' not found", command))
+			} else if err != nil && !errors.Is(err, js.ErrExitCode) {
+				logger.Fatal(1, fmt.Errorf("failed to execute script: %w", err))
+			}
+			return
 		} else if err != nil {
 			logger.Fatal(1, fmt.Errorf("failed to get command: %w", err))
 		}
@@ -561,3 +804,107 @@ func parseCommandlineContext(args []string, parseCtxImmediately bool) map[string
 	}
 	return ctx
 }
+
+// highlight colors the matched ranges of a search result.
+func highlight(s string, ranges []search.Range) string {
+	var b strings.Builder
+	for _, frag := range search.Highlight(s, ranges) {
+		if frag.Match {
+			b.WriteString(quickgo.Craft(quickgo.CMD_Yellow+quickgo.CMD_Bold, frag.Text))
+		} else {
+			b.WriteString(frag.Text)
+		}
+	}
+	return b.String()
+}
+
+// printProjectInfo prints the details of a project, skipping empty fields.
+func printProjectInfo(info *quickgo.ProjectInfo) {
+	var field = func(name string, value any) {
+		if s := fmt.Sprint(value); s != "" && s != "0" {
+			fmt.Printf("%s %s\n", quickgo.Craft(quickgo.CMD_Cyan, fmt.Sprintf("%-12s", name+":")), s)
+		}
+	}
+
+	fmt.Println(quickgo.Craft(quickgo.CMD_Red, info.Name))
+	field("Description", info.Description)
+	field("Tags", strings.Join(info.Tags, ", "))
+	field("Author", info.Author)
+	field("Homepage", info.Homepage)
+	field("Min version", info.MinVersion)
+	field("Icon", info.Icon)
+	field("Visibility", info.Visibility)
+	field("Files", info.Files)
+	field("Size", fmt.Sprintf("%d bytes", info.Size))
+	field("Saved", info.ModTime.Format(time.DateTime))
+
+	if len(info.Context) > 0 {
+		fmt.Println(quickgo.Craft(quickgo.CMD_Blue, "Context:"))
+		var keys = make([]string, 0, len(info.Context))
+		for k := range info.Context {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+
+		for _, k := range keys {
+			fmt.Printf("  - %s: %v\n", quickgo.Craft(quickgo.CMD_Cyan, k), info.Context[k])
+		}
+	}
+
+	if len(info.Commands) > 0 {
+		fmt.Println(quickgo.Craft(quickgo.CMD_Blue, "Commands:"))
+		printCommands(info.Commands)
+	}
+}
+
+// printCommands prints the commands with their description, if they have one.
+func printCommands(commands []*quickgo.CommandInfo) {
+	for _, c := range commands {
+		var description = c.Description
+		if c.Script != "" {
+			description = strings.TrimSpace(fmt.Sprintf("%s (%s)", description, c.Script))
+		}
+
+		if description == "" {
+			fmt.Printf("  - %s\n", quickgo.Craft(quickgo.CMD_Cyan, c.Name))
+			continue
+		}
+		fmt.Printf("  - %s: %s\n", quickgo.Craft(quickgo.CMD_Cyan, c.Name), description)
+	}
+}
+
+// commandInfos turns the names of global commands into command infos.
+func commandInfos(names []string) []*quickgo.CommandInfo {
+	var infos = make([]*quickgo.CommandInfo, 0, len(names))
+	for _, name := range names {
+		infos = append(infos, &quickgo.CommandInfo{Name: name})
+	}
+	return infos
+}
+
+// writeOutput writes v to stdout in the output format, or calls table for the table format.
+func writeOutput(format string, v any, table func() error) {
+	if err := quickgo.WriteOutput(os.Stdout, format, v, table); err != nil {
+		logger.Fatal(1, fmt.Errorf("failed to write output: %w", err))
+	}
+}
+
+type (
+	// usageInfo is the machine-readable output of the usage screen.
+	usageInfo struct {
+		Commands        []*quickgo.CommandInfo `json:"commands" yaml:"commands"`
+		ProjectCommands []*quickgo.CommandInfo `json:"projectCommands" yaml:"projectCommands"`
+		ProjectScripts  []*quickgo.CommandInfo `json:"projectScripts" yaml:"projectScripts"`
+	}
+
+	// remoteInfo describes a configured remote server.
+	remoteInfo struct {
+		Name string `json:"name" yaml:"name"`
+		URL  string `json:"url" yaml:"url"`
+	}
+
+	// switchWriter writes to a writer which can be swapped out.
+	switchWriter struct {
+		io.Writer
+	}
+)
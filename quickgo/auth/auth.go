@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+const (
+	PermissionRead    Permission = iota // Allowed to read templates which are visible to the principal.
+	PermissionPublish                   // Allowed to read all templates and to publish templates.
+)
+
+const (
+	VisibilityPublic  = "public"  // Visible to everyone, including anonymous users. This is the default.
+	VisibilityPrivate = "private" // Visible to all authenticated users.
+	VisibilityTeam    = "team"    // Visible to authenticated users which are a member of one of the template's teams.
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidPermission  = errors.New("invalid permission, expected 'read' or 'publish'")
+	ErrInvalidVisibility  = errors.New("invalid visibility, expected 'public', 'private' or 'team'")
+)
+
+type (
+	// Permission is what an authenticated principal is allowed to do.
+	Permission int
+
+	// Principal is an authenticated user or token.
+	Principal struct {
+		// The name of the user or token, used for logging.
+		Name string
+
+		// The teams the principal is a member of.
+		Teams []string
+
+		// What the principal is allowed to do.
+		Permission Permission
+	}
+
+	// Authenticator authenticates incoming requests.
+	Authenticator interface {
+		// Authenticate returns the principal for the request.
+		//
+		// It returns nil, nil if the request does not carry any credentials
+		// this authenticator knows about, and ErrInvalidCredentials if it does
+		// but they are not valid.
+		Authenticate(r *http.Request) (*Principal, error)
+	}
+
+	// Chain tries each authenticator in order until one of them returns a principal or an error.
+	Chain []Authenticator
+
+	contextKey struct{}
+)
+
+// ParsePermission parses a permission from the configuration.
+// An empty string is the read permission.
+func ParsePermission(s string) (Permission, error) {
+	switch s {
+	case "", "read":
+		return PermissionRead, nil
+	case "publish":
+		return PermissionPublish, nil
+	}
+	return PermissionRead, fmt.Errorf("%w: %q", ErrInvalidPermission, s)
+}
+
+func (p Permission) String() string {
+	switch p {
+	case PermissionRead:
+		return "read"
+	case PermissionPublish:
+		return "publish"
+	}
+	return fmt.Sprintf("Permission(%d)", int(p))
+}
+
+// ValidateVisibility returns an error if the visibility of a template is not known.
+func ValidateVisibility(visibility string) error {
+	switch visibility {
+	case "", VisibilityPublic, VisibilityPrivate, VisibilityTeam:
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrInvalidVisibility, visibility)
+}
+
+// CanPublish reports whether the principal is allowed to publish templates.
+// A nil principal is an anonymous user.
+func (p *Principal) CanPublish() bool {
+	return p != nil && p.Permission >= PermissionPublish
+}
+
+// CanRead reports whether the principal is allowed to read a template
+// with the given visibility and teams.
+// A nil principal is an anonymous user.
+func (p *Principal) CanRead(visibility string, teams []string) bool {
+	switch {
+	case visibility == "" || visibility == VisibilityPublic:
+		return true
+	case p == nil:
+		return false
+	case p.CanPublish() || visibility == VisibilityPrivate:
+		return true
+	case visibility == VisibilityTeam:
+		for _, team := range p.Teams {
+			if slices.Contains(teams, team) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c Chain) Authenticate(r *http.Request) (*Principal, error) {
+	for _, a := range c {
+		var p, err = a.Authenticate(r)
+		if err != nil || p != nil {
+			return p, err
+		}
+	}
+	return nil, nil
+}
+
+// NewContext returns a copy of ctx which carries the principal.
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the principal stored in ctx.
+// It returns nil for anonymous users.
+func FromContext(ctx context.Context) *Principal {
+	var p, _ = ctx.Value(contextKey{}).(*Principal)
+	return p
+}
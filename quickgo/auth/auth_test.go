@@ -0,0 +1,144 @@
+package auth_test
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/auth"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestCanRead(t *testing.T) {
+	var (
+		reader    = &auth.Principal{Name: "reader", Teams: []string{"backend"}}
+		publisher = &auth.Principal{Name: "publisher", Permission: auth.PermissionPublish}
+	)
+
+	var tests = []struct {
+		name       string
+		principal  *auth.Principal
+		visibility string
+		teams      []string
+		expected   bool
+	}{
+		{"anonymous default", nil, "", nil, true},
+		{"anonymous public", nil, auth.VisibilityPublic, nil, true},
+		{"anonymous private", nil, auth.VisibilityPrivate, nil, false},
+		{"anonymous team", nil, auth.VisibilityTeam, []string{"backend"}, false},
+		{"reader private", reader, auth.VisibilityPrivate, nil, true},
+		{"reader team member", reader, auth.VisibilityTeam, []string{"frontend", "backend"}, true},
+		{"reader not team member", reader, auth.VisibilityTeam, []string{"frontend"}, false},
+		{"publisher team", publisher, auth.VisibilityTeam, []string{"frontend"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.principal.CanRead(test.visibility, test.teams); got != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+
+	if (*auth.Principal)(nil).CanPublish() || reader.CanPublish() || !publisher.CanPublish() {
+		t.Errorf("expected only the publisher to be allowed to publish")
+	}
+}
+
+func TestParsePermission(t *testing.T) {
+	if p, err := auth.ParsePermission(""); err != nil || p != auth.PermissionRead {
+		t.Errorf("expected read permission, got %v (%v)", p, err)
+	}
+	if p, err := auth.ParsePermission("publish"); err != nil || p != auth.PermissionPublish {
+		t.Errorf("expected publish permission, got %v (%v)", p, err)
+	}
+	if _, err := auth.ParsePermission("admin"); !errors.Is(err, auth.ErrInvalidPermission) {
+		t.Errorf("expected ErrInvalidPermission, got %v", err)
+	}
+}
+
+func TestTokenAuthenticator(t *testing.T) {
+	var (
+		ci    = &auth.Principal{Name: "ci", Permission: auth.PermissionPublish}
+		chain = auth.Chain{auth.TokenAuthenticator{"secret": ci}}
+	)
+
+	var tests = []struct {
+		name      string
+		header    string
+		principal *auth.Principal
+		err       error
+	}{
+		{"no credentials", "", nil, nil},
+		{"valid token", "Bearer secret", ci, nil},
+		{"invalid token", "Bearer wrong", nil, auth.ErrInvalidCredentials},
+		{"empty token", "Bearer ", nil, auth.ErrInvalidCredentials},
+		{"other scheme", "Basic dXNlcjpwYXNz", nil, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var r, _ = http.NewRequest(http.MethodGet, "/", nil)
+			if test.header != "" {
+				r.Header.Set("Authorization", test.header)
+			}
+
+			var p, err = chain.Authenticate(r)
+			if p != test.principal || !errors.Is(err, test.err) {
+				t.Errorf("expected %v (%v), got %v (%v)", test.principal, test.err, p, err)
+			}
+		})
+	}
+}
+
+func TestHtpasswdAuthenticator(t *testing.T) {
+	var bcryptHash, err = bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hashes, err := auth.ParseHtpasswd(strings.NewReader(strings.Join([]string{
+		"# quickgo users",
+		"bcrypt:" + string(bcryptHash),
+		"apr1:$apr1$abcdefgh$h9FWgUz3n9YxylKLlR5SQ/",
+		"sha:{SHA}5en6G6MezRroT3XKqkdPOmY/BfQ=",
+		"plain:secret",
+		"",
+	}, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var a = &auth.HtpasswdAuthenticator{
+		Hashes: hashes,
+		Users: map[string]*auth.Principal{
+			"bcrypt": {Name: "bcrypt", Permission: auth.PermissionPublish},
+		},
+	}
+
+	for _, user := range []string{"bcrypt", "apr1", "sha", "plain"} {
+		t.Run(user, func(t *testing.T) {
+			var r, _ = http.NewRequest(http.MethodGet, "/", nil)
+			r.SetBasicAuth(user, "secret")
+
+			var p, err = a.Authenticate(r)
+			if err != nil || p == nil || p.Name != user {
+				t.Fatalf("expected principal %q, got %v (%v)", user, p, err)
+			}
+
+			if p.CanPublish() != (user == "bcrypt") {
+				t.Errorf("unexpected publish permission for %q", user)
+			}
+
+			r.SetBasicAuth(user, "wrong")
+			if _, err = a.Authenticate(r); !errors.Is(err, auth.ErrInvalidCredentials) {
+				t.Errorf("expected ErrInvalidCredentials, got %v", err)
+			}
+		})
+	}
+
+	if _, err = auth.ParseHtpasswd(strings.NewReader("invalid")); err == nil {
+		t.Errorf("expected error for invalid htpasswd entry")
+	}
+}
@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// HtpasswdAuthenticator authenticates requests with HTTP basic authentication
+// against the users of an htpasswd file.
+//
+// Supported hashes are bcrypt, apr1 (MD5), {SHA} and plain text.
+type HtpasswdAuthenticator struct {
+	// The password hashes of the users.
+	// [username] => [hash]
+	Hashes map[string]string
+
+	// Teams and permissions of the users.
+	// Users which are not in this map only have the read permission.
+	// [username] => [principal]
+	Users map[string]*Principal
+}
+
+// LoadHtpasswd reads the htpasswd file at path.
+func LoadHtpasswd(path string) (map[string]string, error) {
+	var f, err = os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseHtpasswd(f)
+}
+
+// ParseHtpasswd parses the lines of an htpasswd file.
+// Empty lines and lines starting with '#' are ignored.
+func ParseHtpasswd(r io.Reader) (map[string]string, error) {
+	var (
+		hashes  = make(map[string]string)
+		scanner = bufio.NewScanner(r)
+		line    = 0
+	)
+
+	for scanner.Scan() {
+		line++
+		var text = strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		var user, hash, ok = strings.Cut(text, ":")
+		if !ok || user == "" || hash == "" {
+			return nil, fmt.Errorf("invalid htpasswd entry on line %d", line)
+		}
+
+		hashes[user] = hash
+	}
+
+	return hashes, scanner.Err()
+}
+
+func (h *HtpasswdAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	var user, password, ok = r.BasicAuth()
+	if !ok {
+		return nil, nil
+	}
+
+	var hash, exists = h.Hashes[user]
+	if !exists || !checkHtpasswdHash(hash, password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	if p, ok := h.Users[user]; ok {
+		return p, nil
+	}
+
+	return &Principal{
+		Name:       user,
+		Permission: PermissionRead,
+	}, nil
+}
+
+func checkHtpasswdHash(hash, password string) bool {
+	switch {
+	case strings.HasPrefix(hash, "$2y$"), strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"):
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	case strings.HasPrefix(hash, "$apr1$"):
+		var salt, _, _ = strings.Cut(strings.TrimPrefix(hash, "$apr1$"), "$")
+		return subtle.ConstantTimeCompare([]byte(apr1(password, salt)), []byte(hash)) == 1
+	case strings.HasPrefix(hash, "{SHA}"):
+		var sum = sha1.Sum([]byte(password))
+		var encoded = "{SHA}" + base64.StdEncoding.EncodeToString(sum[:])
+		return subtle.ConstantTimeCompare([]byte(encoded), []byte(hash)) == 1
+	}
+	return subtle.ConstantTimeCompare([]byte(password), []byte(hash)) == 1
+}
+
+// apr1 computes Apache's MD5 based password hash.
+func apr1(password, salt string) string {
+	const (
+		magic  = "$apr1$"
+		itoa64 = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	)
+
+	if len(salt) > 8 {
+		salt = salt[:8]
+	}
+
+	var pw = []byte(password)
+	var alt = md5.Sum([]byte(password + salt + password))
+
+	var h = md5.New()
+	h.Write(pw)
+	h.Write([]byte(magic + salt))
+	for i := len(pw); i > 0; i -= 16 {
+		h.Write(alt[:min(16, i)])
+	}
+	for i := len(pw); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			h.Write([]byte{0})
+		} else {
+			h.Write(pw[:1])
+		}
+	}
+	var sum = h.Sum(nil)
+
+	for i := 0; i < 1000; i++ {
+		h.Reset()
+		if i&1 != 0 {
+			h.Write(pw)
+		} else {
+			h.Write(sum)
+		}
+		if i%3 != 0 {
+			h.Write([]byte(salt))
+		}
+		if i%7 != 0 {
+			h.Write(pw)
+		}
+		if i&1 != 0 {
+			h.Write(sum)
+		} else {
+			h.Write(pw)
+		}
+		sum = h.Sum(nil)
+	}
+
+	var out = new(strings.Builder)
+	var encode = func(v uint, n int) {
+		for ; n > 0; n-- {
+			out.WriteByte(itoa64[v&0x3f])
+			v >>= 6
+		}
+	}
+
+	encode(uint(sum[0])<<16|uint(sum[6])<<8|uint(sum[12]), 4)
+	encode(uint(sum[1])<<16|uint(sum[7])<<8|uint(sum[13]), 4)
+	encode(uint(sum[2])<<16|uint(sum[8])<<8|uint(sum[14]), 4)
+	encode(uint(sum[3])<<16|uint(sum[9])<<8|uint(sum[15]), 4)
+	encode(uint(sum[4])<<16|uint(sum[10])<<8|uint(sum[5]), 4)
+	encode(uint(sum[11]), 2)
+
+	return magic + salt + "$" + out.String()
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// TokenAuthenticator authenticates requests with a static bearer token.
+//
+// [token] => [principal]
+type TokenAuthenticator map[string]*Principal
+
+func (t TokenAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	var token, ok = strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return nil, nil
+	}
+
+	// Compare against every token to not leak which one matched through timing.
+	var principal *Principal
+	for t, p := range t {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			principal = p
+		}
+	}
+
+	if principal == nil || token == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	return principal, nil
+}
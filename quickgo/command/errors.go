@@ -1,6 +1,15 @@
 package command
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrStepInvalid   = errors.New("step is invalid")
+	ErrJSUnavailable = errors.New("JS steps are not available")
+)
 
 type Error struct {
 	// The error message.
@@ -9,13 +9,54 @@ import (
 	"slices"
 
 	"github.com/Nigel2392/quickgo/v2/quickgo/logger"
+	"github.com/pkg/errors"
 )
 
 // CommandStep represents a command to run.
+//
+// A step either runs an external command, an inline JS script or a named JS command.
 type Step struct {
-	Name    string   `yaml:"name"`    // The name of the step.
-	Command string   `yaml:"command"` // The command to run.
-	Args    []string `yaml:"args"`    // The arguments to pass to the command.
+	Name      string   `yaml:"name" json:"name"`                               // The name of the step.
+	Command   string   `yaml:"command" json:"command"`                         // The command to run.
+	Args      []string `yaml:"args" json:"args"`                               // The arguments to pass to the command.
+	Script    string   `yaml:"script,omitempty" json:"script,omitempty"`       // An inline JS script, the body of its main function.
+	JSCommand string   `yaml:"jsCommand,omitempty" json:"jsCommand,omitempty"` // The name of a JS command to run, a project script or a global command.
+}
+
+// JSRunner runs a JS step with the environment of the step.
+// The values returned by the script are added to the environment of the next steps.
+type JSRunner func(step Step, env map[string]any) (map[string]any, error)
+
+// RunJS runs the JS steps, it is set by the quickgo package.
+// JS steps fail with ErrJSUnavailable if it is nil.
+var RunJS JSRunner
+
+// IsJS reports whether the step runs a JS script or command instead of an external command.
+func (s *Step) IsJS() bool {
+	return s.Script != "" || s.JSCommand != ""
+}
+
+// Validate checks that the step runs exactly one command or script.
+func (s *Step) Validate() error {
+	var kinds = 0
+	for _, v := range []string{s.Command, s.Script, s.JSCommand} {
+		if v != "" {
+			kinds++
+		}
+	}
+
+	if kinds != 1 {
+		return errors.Wrapf(ErrStepInvalid, "step '%s' must have exactly one of command, script or jsCommand", s.Name)
+	}
+	return nil
+}
+
+// ExecuteJS runs the JS script or command of the step and returns the values it returned.
+func (s Step) ExecuteJS(env map[string]any) (map[string]any, error) {
+	if RunJS == nil {
+		return nil, ErrJSUnavailable
+	}
+	return RunJS(s, env)
 }
 
 // ParseArgs parses the arguments.
@@ -38,7 +79,13 @@ func (s *Step) ParseArgs(env map[string]any) ([]string, []string) {
 }
 
 // Execute runs the command.
+// JS steps are run with ExecuteJS, their values are discarded.
 func (s Step) Execute(env map[string]any) ([]byte, []byte, error) {
+	if s.IsJS() {
+		var _, err = s.ExecuteJS(env)
+		return nil, nil, err
+	}
+
 	var (
 		args     []string
 		envSlice []string
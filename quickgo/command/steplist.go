@@ -1,18 +1,42 @@
 package command
 
-import "github.com/Nigel2392/quickgo/v2/quickgo/logger"
+import (
+	"maps"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/logger"
+)
 
 type StepList struct {
-	Steps []Step `yaml:"steps"`
+	Steps []Step `yaml:"steps" json:"steps"`
 }
 
+// Execute runs the steps in order.
+// Values returned by JS steps are added to the environment of the steps after them,
+// the environment passed in is not changed.
 func (l *StepList) Execute(env map[string]any) error {
 	if l == nil {
 		return nil
 	}
+
+	env = maps.Clone(env)
 	for _, step := range l.Steps {
 		logger.Infof("Executing step: '%s'", step.Name)
-		if _, _, err := step.Execute(env); err != nil {
+
+		var err = step.Validate()
+		if err == nil && step.IsJS() {
+			var values map[string]any
+			values, err = step.ExecuteJS(env)
+			if err == nil && len(values) > 0 {
+				if env == nil {
+					env = make(map[string]any, len(values))
+				}
+				maps.Copy(env, values)
+			}
+		} else if err == nil {
+			_, _, err = step.Execute(env)
+		}
+
+		if err != nil {
 			return &Error{
 				Message:  "failed to execute step",
 				Err:      err,
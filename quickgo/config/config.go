@@ -6,10 +6,15 @@ import (
 	"fmt"
 	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/Nigel2392/quickgo/v2/quickgo/auth"
 	"github.com/Nigel2392/quickgo/v2/quickgo/command"
+	"github.com/Nigel2392/quickgo/v2/quickgo/js"
 	"github.com/Nigel2392/quickgo/v2/quickgo/logger"
 	"github.com/Nigel2392/quickgo/v2/quickgo/quickfs"
 	"github.com/pkg/errors"
@@ -24,6 +29,9 @@ const (
 	PROJECT_ZIP_NAME    = "project.zip"  // The name of the project zip file.
 	PROJECTS_DIR        = "projects"     // The directory for project files, resides in the executable directory.
 	COMMANDS_DIR        = "commands"     // The directory for command javscript files, resides in the executable directory.
+	COMMANDS_LIB_DIR    = "lib"          // The directory for modules shared by commands, resides in the commands directory.
+	CACHE_DIR           = "cache"        // The directory for templates fetched from remotes, resides in the executable directory.
+	SEARCH_INDEX_NAME   = "search.idx"   // The search index of the saved projects, resides in the executable directory.
 	LOCKFILE_NAME       = "quickgo.lock" // The lock file name.
 
 	// Error messages.
@@ -31,6 +39,7 @@ const (
 	ErrProjectMissing = ErrorStr("project config not found")
 	ErrProjectExists  = ErrorStr("project config already exists")
 	ErrProjectInvalid = ErrorStr("project config is invalid")
+	ErrRemoteMissing  = ErrorStr("remote not found")
 )
 
 var (
@@ -95,6 +104,81 @@ type (
 		Port    string `yaml:"port"`        // The port to run the server on.
 		TLSKey  string `yaml:"privateKey"`  // The path to the TLS key.
 		TLSCert string `yaml:"certificate"` // The path to the TLS certificate.
+		Store   string `yaml:"store"`       // The backend to store project templates in, defaults to "disk".
+
+		// Remote quickgo servers to fetch templates from.
+		// [name] => [remote]
+		Remotes map[string]*Remote `yaml:"remotes,omitempty"`
+
+		// Authentication and authorization for the server.
+		Auth *Auth `yaml:"auth,omitempty"`
+
+		// Bearer tokens which are allowed to publish templates to this server.
+		//
+		// Deprecated: use Auth.Tokens with the publish permission, see AuthConfig.
+		PublishTokens []string `yaml:"publishTokens,omitempty"`
+
+		// Execute the BeforeCopy and AfterCopy steps of a project when it is generated on the server.
+		// These steps run shell commands on the server, so this is disabled by default.
+		AllowGenerateCommands bool `yaml:"allowGenerateCommands,omitempty"`
+
+		// Timeouts for the server, zero values fall back to the defaults.
+		Timeouts *Timeouts `yaml:"timeouts,omitempty"`
+
+		// The maximum size in bytes of the projects the server keeps in memory, zero falls back to the default.
+		CacheSize int64 `yaml:"cacheSize,omitempty"`
+
+		// A directory with templates and static files which override those of the web UI.
+		// Relative paths are relative to the QuickGo directory.
+		Theme string `yaml:"theme,omitempty"`
+	}
+
+	// Timeouts configures the timeouts of the server.
+	// Durations are written like "30s" or "2m".
+	Timeouts struct {
+		Read     time.Duration `yaml:"read,omitempty"`     // The maximum duration for reading a request, including the body.
+		Write    time.Duration `yaml:"write,omitempty"`    // The maximum duration before timing out writes of a response.
+		Idle     time.Duration `yaml:"idle,omitempty"`     // The maximum time to wait for the next request on a keep-alive connection.
+		Shutdown time.Duration `yaml:"shutdown,omitempty"` // The maximum time to wait for active requests when shutting down.
+	}
+
+	// Auth configures who can read and publish templates on the server.
+	//
+	// Anonymous users can only read public templates,
+	// publishing always requires a principal with the publish permission.
+	Auth struct {
+		// Reject all requests which are not authenticated.
+		Required bool `yaml:"required,omitempty"`
+
+		// Static bearer tokens.
+		Tokens []*AuthToken `yaml:"tokens,omitempty"`
+
+		// Path to an htpasswd file for HTTP basic authentication.
+		Htpasswd string `yaml:"htpasswd,omitempty"`
+
+		// Teams and permissions of the htpasswd users.
+		// [username] => [user]
+		Users map[string]*AuthUser `yaml:"users,omitempty"`
+	}
+
+	// AuthToken is a static bearer token which can be used to authenticate with the server.
+	AuthToken struct {
+		Name       string   `yaml:"name"`                 // The name of the token, used for logging.
+		Token      string   `yaml:"token"`                // The secret token.
+		Teams      []string `yaml:"teams,omitempty"`      // The teams the token is a member of.
+		Permission string   `yaml:"permission,omitempty"` // Either "read" (default) or "publish".
+	}
+
+	// AuthUser holds the teams and permission of an htpasswd user.
+	AuthUser struct {
+		Teams      []string `yaml:"teams,omitempty"`      // The teams the user is a member of.
+		Permission string   `yaml:"permission,omitempty"` // Either "read" (default) or "publish".
+	}
+
+	// Remote represents a remote quickgo server which serves templates.
+	Remote struct {
+		URL   string `yaml:"url"`             // The base URL of the remote server.
+		Token string `yaml:"token,omitempty"` // The bearer token used to authenticate with the remote.
 	}
 
 	// Project represents the configuration for an individual project.
@@ -102,6 +186,14 @@ type (
 		// The name of the project.
 		Name string `yaml:"name" json:"name"`
 
+		// Optional metadata, shown when listing projects.
+		Description string   `yaml:"description,omitempty" json:"description,omitempty"` // A short description of the project.
+		Tags        []string `yaml:"tags,omitempty" json:"tags,omitempty"`               // Tags to filter projects by.
+		Author      string   `yaml:"author,omitempty" json:"author,omitempty"`           // The author of the project.
+		Homepage    string   `yaml:"homepage,omitempty" json:"homepage,omitempty"`       // A URL with more information about the project.
+		MinVersion  string   `yaml:"minVersion,omitempty" json:"minVersion,omitempty"`   // The minimum QuickGo version required to use the project.
+		Icon        string   `yaml:"icon,omitempty" json:"icon,omitempty"`               // Path to an image inside of the project, used as its icon.
+
 		// Optional context for project templates.
 		Context map[string]any `yaml:"context" json:"context"`
 
@@ -110,6 +202,14 @@ type (
 		AfterCopy  *command.StepList `yaml:"afterCopy" json:"afterCopy"`
 		Commands   ProjectCommandMap `yaml:"commands" json:"commands"` // [name] => [steps]
 
+		// Glob patterns of JS commands inside of the project, relative to the project directory.
+		// A script is run with `quickgo <name>`, where the name is the file name without the .js extension.
+		Scripts []string `yaml:"scripts,omitempty" json:"scripts,omitempty"`
+
+		// Permissions granted to the JS commands and steps run inside of the project, on top of the permissions in their header.
+		// Read and write paths are relative to the project directory.
+		Permissions *js.Permissions `yaml:"permissions,omitempty" json:"permissions,omitempty"`
+
 		// Variable delimiters for the project templates.
 		DelimLeft  string `yaml:"delimLeft" json:"delimLeft"`
 		DelimRight string `yaml:"delimRight" json:"delimRight"`
@@ -117,8 +217,14 @@ type (
 		// A list of files to exclude from the project in glob format.
 		Exclude []string `yaml:"exclude" json:"exclude"` // (NYI)
 
+		// Who can see the project when it is served: "public" (default), "private" or "team".
+		Visibility string `yaml:"visibility,omitempty" json:"visibility,omitempty"`
+
+		// The teams which can see the project when its visibility is "team".
+		Teams []string `yaml:"teams,omitempty" json:"teams,omitempty"`
+
 		// The root directory.
-		Root *quickfs.FSDirectory `yaml:"-"`
+		Root *quickfs.FSDirectory `yaml:"-" json:"-"`
 	}
 
 	// ProjectCommand represents a command for a project.
@@ -161,7 +267,9 @@ func init() {
 // ExampleProjectConfig returns an example project configuration.
 func ExampleProjectConfig() *Project {
 	return &Project{
-		Name: "my-project",
+		Name:        "my-project",
+		Description: "An example project",
+		Tags:        []string{"example"},
 		Context: map[string]any{
 			"Name": "My Project",
 		},
@@ -220,15 +328,64 @@ func ExampleProjectConfig() *Project {
 	}
 }
 
+// AuthConfig returns the authentication configuration of the server.
+// The deprecated PublishTokens are added as tokens with the publish permission.
+func (c *QuickGo) AuthConfig() *Auth {
+	if len(c.PublishTokens) == 0 {
+		return c.Auth
+	}
+
+	var a Auth
+	if c.Auth != nil {
+		a = *c.Auth
+	}
+
+	a.Tokens = slices.Clone(a.Tokens)
+	for i, token := range c.PublishTokens {
+		a.Tokens = append(a.Tokens, &AuthToken{
+			Name:       fmt.Sprintf("publishTokens[%d]", i),
+			Token:      token,
+			Permission: auth.PermissionPublish.String(),
+		})
+	}
+	return &a
+}
+
 // Validate validates the project configuration.
 func (p *Project) Validate() error {
 	var name = strings.TrimPrefix(p.Name, ".")
 	if strings.Contains(name, "/") || strings.Contains(name, "\\") || name == "" {
 		return ErrProjectName
 	}
+	if err := auth.ValidateVisibility(p.Visibility); err != nil {
+		return errors.Wrap(ErrProjectInvalid, err.Error())
+	}
+	if p.Icon != "" && !isProjectPath(p.Icon) {
+		return errors.Wrap(ErrProjectInvalid, "icon must be a relative path inside of the project")
+	}
+	for _, pattern := range p.Scripts {
+		if _, err := path.Match(pattern, ""); err != nil || !isProjectPath(pattern) {
+			return errors.Wrapf(ErrProjectInvalid, "script %q must be a relative path or pattern inside of the project", pattern)
+		}
+	}
 	return nil
 }
 
+// isProjectPath reports whether p is a relative, slash separated path which stays inside of the project.
+func isProjectPath(p string) bool {
+	return !path.IsAbs(p) && !strings.Contains(p, "\\") && !slices.Contains(strings.Split(p, "/"), "..")
+}
+
+// HasTag reports whether the project is tagged with the tag, ignoring case.
+func (p *Project) HasTag(tag string) bool {
+	for _, t := range p.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *Project) Command(name string, context map[string]any) (*ProjectCommand, error) {
 	var cmd, ok = p.Commands[name]
 	if !ok {
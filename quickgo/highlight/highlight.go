@@ -0,0 +1,212 @@
+package highlight
+
+import (
+	"bytes"
+	"html/template"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/chroma/v2"
+	chroma_html "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+const (
+	// The chroma style used for the generated CSS.
+	Style = "github"
+
+	// The CSS class of template actions between the project's delimiters.
+	TemplateClass = "quickgo-tpl"
+)
+
+var (
+	cssOnce sync.Once
+	css     template.CSS
+
+	markdown = goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+	)
+)
+
+type (
+	// Line is a single highlighted line of a file.
+	Line struct {
+		// The line number, starting at 1.
+		Number int
+
+		// The highlighted parts of the line.
+		Segments []Segment
+	}
+
+	// Segment is a part of a line with the CSS classes it should be rendered with.
+	Segment struct {
+		Text  string
+		Class string
+	}
+
+	// delimRange is the byte range [start, end) of a template action.
+	delimRange struct {
+		start, end int
+	}
+)
+
+// Highlight splits the content into lines and highlights them based on the extension of the filename.
+//
+// If both delimiters are not empty, template actions between them are highlighted with the TemplateClass,
+// on top of the syntax highlighting of the file.
+func Highlight(filename, content, delimLeft, delimRight string) []Line {
+	var lexer = lexers.Match(path.Base(filename))
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	lexer = chroma.Coalesce(lexer)
+
+	var tokens []chroma.Token
+	if it, err := lexer.Tokenise(nil, content); err == nil {
+		tokens = it.Tokens()
+	} else {
+		tokens = []chroma.Token{{Type: chroma.Text, Value: content}}
+	}
+
+	var (
+		actions = findActions(content, delimLeft, delimRight)
+		lines   = []Line{{Number: 1}}
+		offset  = 0
+		action  = 0
+	)
+
+	var add = func(text, class string) {
+		for {
+			var i = strings.IndexByte(text, '\n')
+			var part = text
+			if i >= 0 {
+				part = text[:i]
+			}
+
+			if part != "" {
+				// Merge with the previous segment if the classes are the same.
+				var line = &lines[len(lines)-1]
+				if n := len(line.Segments); n > 0 && line.Segments[n-1].Class == class {
+					line.Segments[n-1].Text += part
+				} else {
+					line.Segments = append(line.Segments, Segment{Text: part, Class: class})
+				}
+			}
+
+			if i < 0 {
+				return
+			}
+
+			lines = append(lines, Line{Number: len(lines) + 1})
+			text = text[i+1:]
+		}
+	}
+
+	for _, token := range tokens {
+		var (
+			text  = token.Value
+			class = chroma.StandardTypes[token.Type]
+		)
+
+		// Split the token at the boundaries of template actions.
+		for text != "" {
+			for action < len(actions) && actions[action].end <= offset {
+				action++
+			}
+
+			var n = len(text)
+			var inAction = false
+			if action < len(actions) {
+				var a = actions[action]
+				if offset >= a.start {
+					inAction = true
+					n = min(n, a.end-offset)
+				} else {
+					n = min(n, a.start-offset)
+				}
+			}
+
+			var segmentClass = class
+			if inAction {
+				segmentClass = TemplateClass
+			}
+
+			add(text[:n], segmentClass)
+			text = text[n:]
+			offset += n
+		}
+	}
+
+	// Content ending in a newline should not show an empty last line.
+	if len(lines) > 1 && len(lines[len(lines)-1].Segments) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// findActions returns the byte ranges of all template actions in content, including the delimiters.
+// An action without a closing delimiter runs until the end of the content.
+func findActions(content, delimLeft, delimRight string) []delimRange {
+	if delimLeft == "" || delimRight == "" {
+		return nil
+	}
+
+	var (
+		actions = make([]delimRange, 0)
+		offset  = 0
+	)
+
+	for {
+		var start = strings.Index(content[offset:], delimLeft)
+		if start < 0 {
+			return actions
+		}
+		start += offset
+
+		var end = strings.Index(content[start+len(delimLeft):], delimRight)
+		if end < 0 {
+			return append(actions, delimRange{start: start, end: len(content)})
+		}
+		end += start + len(delimLeft) + len(delimRight)
+
+		actions = append(actions, delimRange{start: start, end: end})
+		offset = end
+	}
+}
+
+// CSS returns the stylesheet for the classes used in highlighted lines.
+// The lines must be rendered inside an element with the "chroma" class.
+func CSS() template.CSS {
+	cssOnce.Do(func() {
+		var b = new(bytes.Buffer)
+		var formatter = chroma_html.New(chroma_html.WithClasses(true))
+		if err := formatter.WriteCSS(b, styles.Get(Style)); err == nil {
+			css = template.CSS(b.String())
+		}
+	})
+	return css
+}
+
+// IsMarkdown reports whether the file should be rendered as markdown.
+func IsMarkdown(filename string) bool {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+// Markdown renders markdown source to HTML.
+// Raw HTML in the source is not rendered.
+func Markdown(source []byte) (template.HTML, error) {
+	var b = new(bytes.Buffer)
+	if err := markdown.Convert(source, b); err != nil {
+		return "", err
+	}
+	return template.HTML(b.String()), nil
+}
@@ -0,0 +1,81 @@
+package highlight_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/highlight"
+)
+
+func lineText(line highlight.Line) string {
+	var b strings.Builder
+	for _, seg := range line.Segments {
+		b.WriteString(seg.Text)
+	}
+	return b.String()
+}
+
+func TestHighlight(t *testing.T) {
+	var content = "package main\n\nfunc {{ .Name }}() {}\n"
+	var lines = highlight.Highlight("main.go", content, "{{", "}}")
+
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %+v", len(lines), lines)
+	}
+
+	for i, expected := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		if lines[i].Number != i+1 {
+			t.Errorf("expected line number %d, got %d", i+1, lines[i].Number)
+		}
+		if got := lineText(lines[i]); got != expected {
+			t.Errorf("line %d: expected %q, got %q", i+1, expected, got)
+		}
+	}
+
+	var keyword = lines[0].Segments[0]
+	if keyword.Text != "package" || keyword.Class == "" {
+		t.Errorf("expected 'package' to be highlighted as a keyword, got %+v", keyword)
+	}
+
+	var action strings.Builder
+	for _, seg := range lines[2].Segments {
+		if seg.Class == highlight.TemplateClass {
+			action.WriteString(seg.Text)
+		}
+	}
+
+	if action.String() != "{{ .Name }}" {
+		t.Errorf("expected template action to be highlighted, got %q", action.String())
+	}
+}
+
+func TestHighlightUnclosedAction(t *testing.T) {
+	var lines = highlight.Highlight("notes.txt", "a ${{ b\nc", "${{", "}}")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %+v", lines)
+	}
+
+	var last = lines[1].Segments[len(lines[1].Segments)-1]
+	if last.Text != "c" || last.Class != highlight.TemplateClass {
+		t.Errorf("expected unclosed action to run until the end, got %+v", last)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	var html, err = highlight.Markdown([]byte("# Title\n\n<script>alert(1)</script>\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(html), "<h1>Title</h1>") {
+		t.Errorf("expected rendered heading, got %q", html)
+	}
+
+	if strings.Contains(string(html), "<script>") {
+		t.Errorf("expected raw HTML not to be rendered, got %q", html)
+	}
+
+	if !highlight.IsMarkdown("README.md") || highlight.IsMarkdown("main.go") {
+		t.Errorf("unexpected IsMarkdown result")
+	}
+}
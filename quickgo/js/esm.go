@@ -0,0 +1,130 @@
+package js
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// The patterns of the ES module syntax which is supported by transformESM.
+// Only statements at the start of a line are recognized.
+var (
+	esmImport        = regexp.MustCompile(`(?m)^([ \t]*)import\s*(?:([A-Za-z_$][\w$]*)\s*,?\s*)?(?:\{([^}]*)\}\s*|\*\s*as\s+([A-Za-z_$][\w$]*)\s*)?from\s*("[^"\n]*"|'[^'\n]*')[ \t]*;?`)
+	esmImportBare    = regexp.MustCompile(`(?m)^([ \t]*)import\s*("[^"\n]*"|'[^'\n]*')[ \t]*;?`)
+	esmExportDecl    = regexp.MustCompile(`(?m)^([ \t]*)export\s+(default\s+)?((?:async\s+)?function\s*\*?\s*([A-Za-z_$][\w$]*)|class\s+([A-Za-z_$][\w$]*)|(?:const|let|var)\s+([A-Za-z_$][\w$]*))`)
+	esmExportList    = regexp.MustCompile(`(?m)^([ \t]*)export\s*\{([^}]*)\}\s*(?:from\s*("[^"\n]*"|'[^'\n]*'))?[ \t]*;?`)
+	esmExportAll     = regexp.MustCompile(`(?m)^([ \t]*)export\s*\*\s*from\s*("[^"\n]*"|'[^'\n]*')[ \t]*;?`)
+	esmExportDefault = regexp.MustCompile(`(?m)^([ \t]*)export\s+default\s+`)
+)
+
+// transformESM rewrites the import and export statements of an ES module to CommonJS,
+// so the module can be loaded with require.
+//
+// The transformed source has the same number of lines, so line numbers in errors stay correct.
+func transformESM(source string) string {
+	var (
+		counter  int
+		exported []string
+	)
+
+	var module = func() string {
+		counter++
+		return fmt.Sprintf("__module%d", counter)
+	}
+
+	// Keep the lines of a statement which spans multiple lines.
+	var lines = func(match string) string {
+		return strings.Repeat("\n", strings.Count(match, "\n"))
+	}
+
+	source = esmImport.ReplaceAllStringFunc(source, func(match string) string {
+		var (
+			groups = esmImport.FindStringSubmatch(match)
+			name   = module()
+			b      = new(strings.Builder)
+		)
+
+		fmt.Fprintf(b, "%svar %s = require(%s);", groups[1], name, groups[5])
+
+		if groups[2] != "" {
+			fmt.Fprintf(b, " var %s = %s && %s.__esModule ? %s[\"default\"] : %s;", groups[2], name, name, name, name)
+		}
+
+		for _, spec := range splitSpecifiers(groups[3]) {
+			fmt.Fprintf(b, " var %s = %s[%q];", spec[1], name, spec[0])
+		}
+
+		if groups[4] != "" {
+			fmt.Fprintf(b, " var %s = %s;", groups[4], name)
+		}
+
+		return b.String() + lines(match)
+	})
+
+	source = esmImportBare.ReplaceAllString(source, "${1}require(${2});")
+
+	source = esmExportAll.ReplaceAllString(source, "${1}Object.assign(exports, require(${2}));")
+
+	source = esmExportList.ReplaceAllStringFunc(source, func(match string) string {
+		var groups = esmExportList.FindStringSubmatch(match)
+		var specs = splitSpecifiers(groups[2])
+
+		// Re-exports are assigned immediately, local bindings at the end of the module.
+		if groups[3] == "" {
+			for _, spec := range specs {
+				exported = append(exported, fmt.Sprintf("exports[%q] = %s;", spec[1], spec[0]))
+			}
+			return groups[1] + lines(match)
+		}
+
+		var (
+			name = module()
+			b    = new(strings.Builder)
+		)
+
+		fmt.Fprintf(b, "%svar %s = require(%s);", groups[1], name, groups[3])
+		for _, spec := range specs {
+			fmt.Fprintf(b, " exports[%q] = %s[%q];", spec[1], name, spec[0])
+		}
+		return b.String() + lines(match)
+	})
+
+	source = esmExportDecl.ReplaceAllStringFunc(source, func(match string) string {
+		var groups = esmExportDecl.FindStringSubmatch(match)
+		var name = groups[4] + groups[5] + groups[6]
+		if groups[2] != "" {
+			exported = append(exported, fmt.Sprintf("exports[\"default\"] = %s;", name))
+		} else {
+			exported = append(exported, fmt.Sprintf("exports[%q] = %s;", name, name))
+		}
+		return groups[1] + groups[3]
+	})
+
+	var hasDefault = esmExportDefault.MatchString(source)
+	source = esmExportDefault.ReplaceAllString(source, "${1}exports[\"default\"] = ")
+
+	if len(exported) > 0 || hasDefault {
+		source = "Object.defineProperty(exports, \"__esModule\", { value: true }); " + source
+	}
+
+	if len(exported) > 0 {
+		source += "\n" + strings.Join(exported, " ")
+	}
+
+	return source
+}
+
+// splitSpecifiers splits import or export specifiers like `a, b as c` into [name, alias] pairs.
+func splitSpecifiers(specifiers string) [][2]string {
+	var specs = make([][2]string, 0)
+	for _, spec := range strings.Split(specifiers, ",") {
+		var fields = strings.Fields(spec)
+		switch {
+		case len(fields) == 1:
+			specs = append(specs, [2]string{fields[0], fields[0]})
+		case len(fields) == 3 && fields[1] == "as":
+			specs = append(specs, [2]string{fields[0], fields[2]})
+		}
+	}
+	return specs
+}
@@ -0,0 +1,205 @@
+package js
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/pkg/errors"
+)
+
+var ErrMainPending = errors.New("main function returned a promise which never settled")
+
+type (
+	// EventLoop runs timers and the callbacks of asynchronous Go functions on the goroutine of the VM.
+	//
+	// The VM is not safe for concurrent use, Go functions which run in the background
+	// must only touch the VM from a job passed to RunOnLoop.
+	EventLoop struct {
+		vm *goja.Runtime
+
+		// Jobs queued from other goroutines.
+		mu     sync.Mutex
+		queue  []func()
+		wakeup chan struct{}
+
+		// Only used on the goroutine of the VM.
+		pending int
+		timers  map[int64]*timer
+		nextID  int64
+		err     error
+	}
+
+	timer struct {
+		t        *time.Timer
+		fn       goja.Callable
+		args     []goja.Value
+		delay    time.Duration
+		interval bool
+	}
+)
+
+// NewEventLoop returns an event loop for the VM.
+func NewEventLoop(vm *goja.Runtime) *EventLoop {
+	return &EventLoop{
+		vm:     vm,
+		wakeup: make(chan struct{}, 1),
+		timers: make(map[int64]*timer),
+	}
+}
+
+// RunOnLoop queues the job to run on the loop, it is safe to call from any goroutine.
+func (l *EventLoop) RunOnLoop(job func(vm *goja.Runtime)) {
+	l.mu.Lock()
+	l.queue = append(l.queue, func() { job(l.vm) })
+	l.mu.Unlock()
+
+	select {
+	case l.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// Async runs work on a new goroutine and returns a promise which settles with its result.
+// Byte slices are resolved as an ArrayBuffer, other results are converted with ToValue.
+//
+// Async must be called on the loop, e.g. from a function called by the script.
+func (l *EventLoop) Async(work func() (any, error)) goja.Value {
+	var promise, resolve, reject = l.vm.NewPromise()
+
+	l.pending++
+	go func() {
+		var result, err = work()
+		l.RunOnLoop(func(vm *goja.Runtime) {
+			l.pending--
+			if err != nil {
+				reject(vm.NewGoError(err))
+				return
+			}
+
+			if b, ok := result.([]byte); ok {
+				resolve(vm.NewArrayBuffer(b))
+				return
+			}
+			resolve(result)
+		})
+	}()
+
+	return l.vm.ToValue(promise)
+}
+
+// install adds the timer functions to the VM.
+func (l *EventLoop) install() {
+	l.vm.Set("setTimeout", func(call goja.FunctionCall) goja.Value {
+		return l.schedule(call, false)
+	})
+	l.vm.Set("setInterval", func(call goja.FunctionCall) goja.Value {
+		return l.schedule(call, true)
+	})
+	l.vm.Set("clearTimeout", l.clear)
+	l.vm.Set("clearInterval", l.clear)
+}
+
+func (l *EventLoop) schedule(call goja.FunctionCall, interval bool) goja.Value {
+	var fn, ok = goja.AssertFunction(call.Argument(0))
+	if !ok {
+		panic(l.vm.NewTypeError("callback must be a function"))
+	}
+
+	var delay = time.Duration(call.Argument(1).ToInteger()) * time.Millisecond
+	if interval && delay < time.Millisecond {
+		delay = time.Millisecond
+	}
+
+	// The arguments are only valid during the call, they must be copied.
+	var args []goja.Value
+	if len(call.Arguments) > 2 {
+		args = slices.Clone(call.Arguments[2:])
+	}
+
+	l.nextID++
+	var id = l.nextID
+	var t = &timer{
+		fn:       fn,
+		args:     args,
+		delay:    delay,
+		interval: interval,
+	}
+
+	l.timers[id] = t
+	l.pending++
+	l.arm(id, t)
+
+	return l.vm.ToValue(id)
+}
+
+func (l *EventLoop) arm(id int64, t *timer) {
+	t.t = time.AfterFunc(t.delay, func() {
+		l.RunOnLoop(func(*goja.Runtime) {
+			l.fire(id)
+		})
+	})
+}
+
+func (l *EventLoop) fire(id int64) {
+	var t, ok = l.timers[id]
+	if !ok {
+		// The timer was cleared after it fired.
+		return
+	}
+
+	if !t.interval {
+		delete(l.timers, id)
+		l.pending--
+	}
+
+	if _, err := t.fn(goja.Undefined(), t.args...); err != nil {
+		l.fail(err)
+		return
+	}
+
+	if _, ok = l.timers[id]; ok && t.interval {
+		l.arm(id, t)
+	}
+}
+
+func (l *EventLoop) clear(id int64) {
+	if t, ok := l.timers[id]; ok {
+		t.t.Stop()
+		delete(l.timers, id)
+		l.pending--
+	}
+}
+
+// fail stops the loop with the error.
+func (l *EventLoop) fail(err error) {
+	if l.err == nil {
+		l.err = err
+	}
+	for id, t := range l.timers {
+		t.t.Stop()
+		delete(l.timers, id)
+	}
+}
+
+// wait runs queued jobs until there are no timers or asynchronous functions left,
+// or until a callback throws an error.
+func (l *EventLoop) wait() error {
+	for l.pending > 0 && l.err == nil {
+		<-l.wakeup
+
+		l.mu.Lock()
+		var jobs = l.queue
+		l.queue = nil
+		l.mu.Unlock()
+
+		for _, job := range jobs {
+			if l.err != nil {
+				break
+			}
+			job()
+		}
+	}
+	return l.err
+}
@@ -0,0 +1,94 @@
+package js_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/js"
+	"github.com/dop251/goja"
+)
+
+func TestEventLoop(t *testing.T) {
+	var (
+		vm   = goja.New()
+		loop = js.NewEventLoop(vm)
+	)
+
+	vm.Set("sleep", func(ms int) goja.Value {
+		return loop.Async(func() (any, error) {
+			time.Sleep(time.Duration(ms) * time.Millisecond)
+			return ms, nil
+		})
+	})
+	vm.Set("fail", func() goja.Value {
+		return loop.Async(func() (any, error) {
+			return nil, errors.New("async failure")
+		})
+	})
+
+	var cmd = js.NewScript("main", js.WithVM(vm), js.WithEventLoop(loop))
+	var result, err = cmd.Run(`
+		const order = [];
+
+		async function main() {
+			setTimeout((v) => order.push(v), 20, "timeout");
+			const cleared = setTimeout(() => order.push("cleared"), 5);
+			clearTimeout(cleared);
+
+			let ticks = 0;
+			const interval = setInterval(() => {
+				if (++ticks === 3) {
+					clearInterval(interval);
+					order.push("interval");
+				}
+			}, 1);
+
+			const slept = await Promise.all([sleep(10), sleep(1)]);
+			order.push("slept " + slept.join(","));
+
+			try {
+				await fail();
+			} catch (e) {
+				order.push("caught " + e.message);
+			}
+
+			await new Promise((resolve) => setTimeout(resolve, 30));
+			return Success(order.join("|"));
+		}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, part := range []string{"interval", "slept 10,1", "caught async failure", "timeout"} {
+		if !strings.Contains(result.Message, part) {
+			t.Errorf("expected %q in %q", part, result.Message)
+		}
+	}
+	if strings.Contains(result.Message, "cleared") {
+		t.Errorf("expected the cleared timeout not to run, got %q", result.Message)
+	}
+
+	t.Run("rejected", func(t *testing.T) {
+		var _, err = js.NewScript("main").Run(`async function main() { throw new Error("rejected"); }`)
+		if err == nil || !strings.Contains(err.Error(), "rejected") {
+			t.Errorf("expected the rejection as error, got %v", err)
+		}
+	})
+
+	t.Run("pending", func(t *testing.T) {
+		var _, err = js.NewScript("main").Run(`function main() { return new Promise(() => {}); }`)
+		if !errors.Is(err, js.ErrMainPending) {
+			t.Errorf("expected %v, got %v", js.ErrMainPending, err)
+		}
+	})
+
+	t.Run("callback error", func(t *testing.T) {
+		var _, err = js.NewScript("main").Run(`function main() { setTimeout(() => { throw new Error("in callback"); }, 1); }`)
+		if err == nil || !strings.Contains(err.Error(), "in callback") {
+			t.Errorf("expected the error of the callback, got %v", err)
+		}
+	})
+}
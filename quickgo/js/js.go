@@ -2,6 +2,7 @@ package js
 
 import (
 	"maps"
+	"path/filepath"
 
 	"github.com/dop251/goja"
 	"github.com/pkg/errors"
@@ -15,6 +16,13 @@ type (
 		// An override of a VM to use.
 		_VM *goja.Runtime
 
+		// The event loop for timers and promises, a new loop is created if nil.
+		_Loop *EventLoop
+
+		// The path of the script and the paths to search for modules, require and import are only available if set.
+		_Filename string
+		_Paths    []string
+
 		_Globals map[string]any
 		_Funcs   []VMFunc
 	}
@@ -28,6 +36,9 @@ type (
 	CommandResult struct {
 		Importance int    `json:"importance"`
 		Message    string `json:"message"`
+
+		// Values returned by the script, e.g. to add to the environment of the next step.
+		Values map[string]any `json:"values"`
 	}
 
 	OptFunc func(*Command)
@@ -74,6 +85,26 @@ func WithVM(vm *goja.Runtime) OptFunc {
 	}
 }
 
+// WithEventLoop sets the event loop the script runs on.
+// The loop must belong to the VM set with WithVM, Go functions added to the script can use it to return promises.
+func WithEventLoop(loop *EventLoop) OptFunc {
+	return func(s *Command) {
+		s._Loop = loop
+	}
+}
+
+// WithModules enables require and ES import statements in the script.
+//
+// The filename is the path of the script itself, relative module names are resolved against its directory.
+// Other module names are resolved against the search paths, in order.
+// Modules outside of the directory of the script and the search paths cannot be loaded.
+func WithModules(filename string, paths ...string) OptFunc {
+	return func(s *Command) {
+		s._Filename = filename
+		s._Paths = paths
+	}
+}
+
 func NewScript(mainFunc string, options ...OptFunc) (cmd *Command) {
 	var s = &Command{
 		Main:     mainFunc,
@@ -127,6 +158,12 @@ func (s *Command) Run(scriptSource string) (result *CommandResult, err error) {
 		}
 	})
 
+	var loop = s._Loop
+	if loop == nil {
+		loop = NewEventLoop(vm)
+	}
+	loop.install()
+
 	for k, v := range s._Globals {
 		err = vm.Set(k, v)
 		if err != nil {
@@ -140,7 +177,24 @@ func (s *Command) Run(scriptSource string) (result *CommandResult, err error) {
 		}
 	}
 
-	_, err = vm.RunString(scriptSource)
+	if s._Filename != "" {
+		var (
+			modules = newModules(vm, filepath.Dir(s._Filename), s._Paths)
+			exports = vm.NewObject()
+			module  = vm.NewObject()
+		)
+
+		module.Set("exports", exports)
+		vm.Set("module", module)
+		vm.Set("exports", exports)
+		vm.Set("require", modules.require(filepath.Dir(s._Filename)))
+		vm.Set("__filename", s._Filename)
+		vm.Set("__dirname", filepath.Dir(s._Filename))
+
+		_, err = vm.RunScript(s._Filename, transformESM(scriptSource))
+	} else {
+		_, err = vm.RunString(scriptSource)
+	}
 	if err != nil {
 		return nil, errors.Wrap(err, "error running script")
 	}
@@ -150,12 +204,33 @@ func (s *Command) Run(scriptSource string) (result *CommandResult, err error) {
 		return nil, ErrMainMissing
 	}
 
-	var main func() *CommandResult
-	if err = vm.ExportTo(mainFunc, &main); err != nil {
+	var main, ok = goja.AssertFunction(mainFunc)
+	if !ok {
 		return nil, ErrMainInvalid
 	}
 
-	result = main()
+	value, err := main(goja.Undefined())
+	if err != nil {
+		return nil, errors.Wrap(err, "error running main function")
+	}
+
+	// Run timers and asynchronous functions until there is nothing left to do.
+	if err = loop.wait(); err != nil {
+		return nil, errors.Wrap(err, "error running callback")
+	}
+
+	if promise, ok := value.Export().(*goja.Promise); ok {
+		switch promise.State() {
+		case goja.PromiseStateFulfilled:
+			value = promise.Result()
+		case goja.PromiseStateRejected:
+			return nil, errors.Errorf("main function was rejected: %s", promise.Result())
+		default:
+			return nil, ErrMainPending
+		}
+	}
+
+	result = exportResult(value)
 
 	if result.Importance != 0 {
 		return result, &CommandError{
@@ -167,3 +242,28 @@ func (s *Command) Run(scriptSource string) (result *CommandResult, err error) {
 
 	return result, nil
 }
+
+// exportResult converts the value returned by the main function to a result.
+//
+// The main function can return a Result, an object with values for the caller, or nothing at all.
+func exportResult(value goja.Value) *CommandResult {
+	if value == nil || goja.IsUndefined(value) || goja.IsNull(value) {
+		return newResult(0, "")
+	}
+
+	switch v := value.Export().(type) {
+	case *CommandResult:
+		if v == nil {
+			return newResult(0, "")
+		}
+		return v
+	case map[string]any:
+		var result = newResult(0, "")
+		result.Values = v
+		return result
+	case string:
+		return newResult(0, v)
+	}
+
+	return newResult(0, value.String())
+}
@@ -2,6 +2,7 @@ package js_test
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/Nigel2392/quickgo/v2/quickgo/js"
@@ -53,3 +54,30 @@ func TestRunScriptErrorStatusCode(t *testing.T) {
 		t.Fatalf("expected message %s, got %s", retMessage, d.Message)
 	}
 }
+
+func TestRunScriptValues(t *testing.T) {
+	var tests = map[string]struct {
+		script  string
+		message string
+		values  map[string]any
+	}{
+		"object":    {`function main() { return { version: "v1.2.3" }; }`, "", map[string]any{"version": "v1.2.3"}},
+		"undefined": {`function main() {}`, "", nil},
+		"result":    {`function main() { var r = Success("done"); r.values = { ok: true }; return r; }`, "done", map[string]any{"ok": true}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var d, err = js.NewScript("main").Run(test.script)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if d.Message != test.message {
+				t.Errorf("expected message %q, got %q", test.message, d.Message)
+			}
+			if !reflect.DeepEqual(d.Values, test.values) {
+				t.Errorf("expected values %v, got %v", test.values, d.Values)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package js
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dop251/goja"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrModuleNotFound   = errors.New("module not found")
+	ErrModuleNotAllowed = errors.New("module not allowed")
+)
+
+// modules loads CommonJS modules for require.
+//
+// Relative names (./lib/semver) are resolved against the directory of the module
+// which requires them and then against the search paths, other names only against the search paths.
+// The extensions .js and .json and an index.js file in a directory are tried in order.
+//
+// Modules can only be loaded from the directory of the script and the search paths,
+// scripts cannot require files they are not allowed to read.
+type modules struct {
+	vm    *goja.Runtime
+	paths []string
+	roots *Permissions // Read access to the directories modules can be loaded from.
+
+	// Loaded modules by their absolute path.
+	cache map[string]*goja.Object
+}
+
+func newModules(vm *goja.Runtime, dir string, paths []string) *modules {
+	var roots = make([]string, 0, len(paths)+1)
+	for _, root := range append([]string{dir}, paths...) {
+		if abs, err := filepath.Abs(root); err == nil {
+			roots = append(roots, resolvePath(abs))
+		}
+	}
+
+	return &modules{
+		vm:    vm,
+		paths: paths,
+		roots: &Permissions{Read: roots},
+		cache: make(map[string]*goja.Object),
+	}
+}
+
+// require returns the require function for modules in dir.
+func (m *modules) require(dir string) func(goja.FunctionCall) goja.Value {
+	return func(call goja.FunctionCall) goja.Value {
+		var module, err = m.load(dir, call.Argument(0).String())
+		if err != nil {
+			panic(m.vm.NewGoError(err))
+		}
+		return module.Get("exports")
+	}
+}
+
+func (m *modules) resolve(dir, name string) (string, error) {
+	var bases []string
+	switch {
+	case filepath.IsAbs(name):
+		bases = []string{""}
+	case name == "." || name == ".." || strings.HasPrefix(name, "./") || strings.HasPrefix(name, "../"):
+		bases = append([]string{dir}, m.paths...)
+	default:
+		bases = m.paths
+	}
+
+	for _, base := range bases {
+		var p = filepath.Join(base, filepath.FromSlash(name))
+		for _, candidate := range []string{p, p + ".js", p + ".json", filepath.Join(p, "index.js")} {
+			if info, err := os.Stat(candidate); err == nil && info.Mode().IsRegular() {
+				return m.allowed(name, candidate)
+			}
+		}
+	}
+
+	return "", errors.Wrapf(ErrModuleNotFound, "cannot find module '%s'", name)
+}
+
+// allowed returns the absolute path of the module if it is inside of the directory of the script or a search path.
+func (m *modules) allowed(name, path string) (string, error) {
+	var abs, err = filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	if m.roots.CheckRead(abs) != nil {
+		return "", errors.Wrapf(ErrModuleNotAllowed, "cannot require '%s', it is outside of the module directories", name)
+	}
+	return abs, nil
+}
+
+func (m *modules) load(dir, name string) (*goja.Object, error) {
+	var path, err = m.resolve(dir, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if module, ok := m.cache[path]; ok {
+		return module, nil
+	}
+
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read module %s", path)
+	}
+
+	var (
+		module  = m.vm.NewObject()
+		exports = m.vm.NewObject()
+	)
+
+	module.Set("id", path)
+	module.Set("filename", path)
+	module.Set("exports", exports)
+
+	if filepath.Ext(path) == ".json" {
+		var v any
+		if err = json.Unmarshal(source, &v); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse module %s", path)
+		}
+		module.Set("exports", v)
+		m.cache[path] = module
+		return module, nil
+	}
+
+	// Cache the module before running it, so circular requires receive the exports as they are so far.
+	m.cache[path] = module
+
+	// The wrapper is on the first line of the module, so line numbers in errors stay correct.
+	var code = "(function(exports, require, module, __filename, __dirname) {" + transformESM(string(source)) + "\n})"
+	wrapper, err := m.vm.RunScript(path, code)
+	if err != nil {
+		delete(m.cache, path)
+		return nil, errors.Wrapf(err, "failed to compile module %s", path)
+	}
+
+	var fn, _ = goja.AssertFunction(wrapper)
+	_, err = fn(
+		goja.Undefined(),
+		exports,
+		m.vm.ToValue(m.require(filepath.Dir(path))),
+		module,
+		m.vm.ToValue(path),
+		m.vm.ToValue(filepath.Dir(path)),
+	)
+	if err != nil {
+		delete(m.cache, path)
+		return nil, errors.Wrapf(err, "failed to load module %s", path)
+	}
+
+	return module, nil
+}
@@ -0,0 +1,141 @@
+package js_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/js"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		var p = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestModules(t *testing.T) {
+	var (
+		commands = t.TempDir()
+		project  = t.TempDir()
+	)
+
+	writeFiles(t, commands, map[string]string{
+		"lib/semver.js": `
+			const { pad } = require("./strings");
+			module.exports.format = function(major, minor) {
+				return "v" + major + "." + pad(minor);
+			};
+		`,
+		"lib/strings.js": `
+			exports.pad = (n) => String(n).padStart(2, "0");
+		`,
+		"lib/greet/index.js": `
+			import { format } from "../semver";
+
+			export const greeting = "Hello";
+			export default function greet(name) {
+				return greeting + " " + name + " " + format(1, 2);
+			}
+		`,
+		"lib/data.json": `{"name": "quickgo"}`,
+	})
+
+	writeFiles(t, project, map[string]string{
+		"tools/local.js": `module.exports = "local";`,
+	})
+
+	var script = `
+		import greet, { greeting as hello } from "greet";
+		import * as semver from "./lib/semver";
+		const data = require("data.json");
+		const local = require("./tools/local");
+
+		export function main() {
+			const message = [greet(data.name), hello, semver.format(2, 0), local].join("|");
+			return Result(0, message);
+		}
+	`
+
+	var cmd = js.NewScript("main", js.WithModules(
+		filepath.Join(commands, "command.js"),
+		filepath.Join(commands, "lib"),
+		commands,
+		project,
+	))
+
+	var result, err = cmd.Run(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expected = "Hello quickgo v1.02|Hello|v2.00|local"
+	if result.Message != expected {
+		t.Errorf("expected %q, got %q", expected, result.Message)
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		var cmd = js.NewScript("main", js.WithModules(filepath.Join(commands, "command.js")))
+		var _, err = cmd.Run(`
+			function main() {
+				try {
+					require("./does-not-exist");
+				} catch (e) {
+					return Result(1, String(e));
+				}
+				return Result(0, "");
+			}
+		`)
+
+		var cmdErr *js.CommandError
+		if !errors.As(err, &cmdErr) {
+			t.Fatalf("expected a command error, got %v", err)
+		}
+		if !strings.Contains(cmdErr.Message, "cannot find module './does-not-exist'") {
+			t.Errorf("expected a module not found error, got %q", cmdErr.Message)
+		}
+	})
+
+	t.Run("outside", func(t *testing.T) {
+		var outside = t.TempDir()
+		writeFiles(t, outside, map[string]string{
+			"secret.json": `{"token": "secret"}`,
+			"secret.js":   `module.exports = "secret";`,
+		})
+
+		if err := os.Symlink(filepath.Join(outside, "secret.js"), filepath.Join(project, "link.js")); err != nil {
+			t.Fatal(err)
+		}
+
+		var rel, err = filepath.Rel(commands, filepath.Join(outside, "secret.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, name := range []string{
+			filepath.Join(outside, "secret.json"),
+			filepath.ToSlash(rel),
+			"link",
+		} {
+			var cmd = js.NewScript("main", js.WithModules(filepath.Join(commands, "command.js"), project))
+			var _, err = cmd.Run(`
+				function main() {
+					return Result(0, require(` + strconv.Quote(name) + `));
+				}
+			`)
+			if err == nil || !strings.Contains(err.Error(), "outside of the module directories") {
+				t.Errorf("expected %s not to be allowed, got %v", name, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,275 @@
+package js
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The prefix of the header lines which declare the permissions of a script.
+const PermissionsHeader = "// @permissions"
+
+// The kinds of permissions, also used as the flag names in the script header (allow-<kind>).
+const (
+	PermissionRead  = "read"
+	PermissionWrite = "write"
+	PermissionRun   = "run"
+	PermissionEnv   = "env"
+	PermissionNet   = "net"
+)
+
+// Allows everything of a kind when it is in the list of a permission.
+const AllowAny = "*"
+
+var ErrPermissionDenied = errors.New("permission denied")
+
+type (
+	// Permissions are the permissions of a script, modeled after Deno.
+	// Nothing is allowed by default, the lists can contain AllowAny to allow everything of a kind.
+	Permissions struct {
+		All   bool     `yaml:"all,omitempty" json:"all,omitempty"`     // Allow everything.
+		Read  []string `yaml:"read,omitempty" json:"read,omitempty"`   // Paths which can be read, including everything inside of them.
+		Write []string `yaml:"write,omitempty" json:"write,omitempty"` // Paths which can be written, including everything inside of them.
+		Run   []string `yaml:"run,omitempty" json:"run,omitempty"`     // Executables which can be run, exactly as they are passed to os.exec.
+		Env   []string `yaml:"env,omitempty" json:"env,omitempty"`     // Environment variables which can be read and set.
+		Net   []string `yaml:"net,omitempty" json:"net,omitempty"`     // Hosts which can be connected to, optionally with a port.
+	}
+
+	// PermissionError is returned when a script does not have the permission for an operation.
+	PermissionError struct {
+		Kind   string
+		Target string
+	}
+)
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf(
+		"permission denied: %s access to %q, grant it with allow-%s in the permissions of the script or the project, or run with -allow-all",
+		e.Kind, e.Target, e.Kind,
+	)
+}
+
+func (e *PermissionError) Unwrap() error {
+	return ErrPermissionDenied
+}
+
+// ParsePermissions parses the permissions from the header of a script.
+//
+// The header consists of the comment lines at the start of the script.
+// Permissions are declared as flags on lines starting with PermissionsHeader, a flag without value allows everything of its kind:
+//
+//	// @permissions allow-read=.,./templates allow-write=./dist
+//	// @permissions allow-run=git,go allow-env=HOME allow-net=api.example.com
+//	// @permissions allow-all
+func ParsePermissions(source string) (*Permissions, error) {
+	var (
+		perms   = new(Permissions)
+		scanner = bufio.NewScanner(strings.NewReader(source))
+	)
+
+	for scanner.Scan() {
+		var line = strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#!") {
+			continue
+		}
+
+		if !strings.HasPrefix(line, "//") {
+			break
+		}
+
+		var flags, ok = strings.CutPrefix(line, PermissionsHeader)
+		if !ok {
+			continue
+		}
+
+		for _, flag := range strings.Fields(flags) {
+			if err := perms.parseFlag(flag); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return perms, nil
+}
+
+func (p *Permissions) parseFlag(flag string) error {
+	var name, value, hasValue = strings.Cut(strings.TrimLeft(flag, "-"), "=")
+
+	var values = []string{AllowAny}
+	if hasValue {
+		values = strings.Split(value, ",")
+	}
+
+	switch name {
+	case "allow-all":
+		p.All = true
+	case "allow-" + PermissionRead:
+		p.Read = append(p.Read, values...)
+	case "allow-" + PermissionWrite:
+		p.Write = append(p.Write, values...)
+	case "allow-" + PermissionRun:
+		p.Run = append(p.Run, values...)
+	case "allow-" + PermissionEnv:
+		p.Env = append(p.Env, values...)
+	case "allow-" + PermissionNet:
+		p.Net = append(p.Net, values...)
+	default:
+		return errors.Errorf("unknown permission %q", flag)
+	}
+
+	return nil
+}
+
+// Merge returns the permissions of both p and other.
+func (p *Permissions) Merge(other *Permissions) *Permissions {
+	if p == nil {
+		p = new(Permissions)
+	}
+	if other == nil {
+		other = new(Permissions)
+	}
+
+	return &Permissions{
+		All:   p.All || other.All,
+		Read:  slices.Concat(p.Read, other.Read),
+		Write: slices.Concat(p.Write, other.Write),
+		Run:   slices.Concat(p.Run, other.Run),
+		Env:   slices.Concat(p.Env, other.Env),
+		Net:   slices.Concat(p.Net, other.Net),
+	}
+}
+
+// Resolve returns a copy of the permissions where relative read and write paths are made absolute against dir.
+func (p *Permissions) Resolve(dir string) *Permissions {
+	var resolved = p.Merge(nil)
+	for _, paths := range [][]string{resolved.Read, resolved.Write} {
+		for i, path := range paths {
+			if path == AllowAny {
+				continue
+			}
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			paths[i] = resolvePath(path)
+		}
+	}
+	return resolved
+}
+
+// CheckRead returns an error if the path cannot be read.
+func (p *Permissions) CheckRead(path string) error {
+	return p.checkPath(PermissionRead, path)
+}
+
+// CheckWrite returns an error if the path cannot be written.
+func (p *Permissions) CheckWrite(path string) error {
+	return p.checkPath(PermissionWrite, path)
+}
+
+// CheckRun returns an error if the executable cannot be run.
+func (p *Permissions) CheckRun(executable string) error {
+	if p.allowed(p.list(PermissionRun), func(allowed string) bool { return allowed == executable }) {
+		return nil
+	}
+	return &PermissionError{Kind: PermissionRun, Target: executable}
+}
+
+// CheckEnv returns an error if the environment variable cannot be read or set.
+func (p *Permissions) CheckEnv(key string) error {
+	if p.allowed(p.list(PermissionEnv), func(allowed string) bool { return allowed == key }) {
+		return nil
+	}
+	return &PermissionError{Kind: PermissionEnv, Target: key}
+}
+
+// CheckNet returns an error if the host cannot be connected to.
+// The host can include a port, hosts in the permissions without a port allow all ports.
+func (p *Permissions) CheckNet(host string) error {
+	var hostname, _, err = net.SplitHostPort(host)
+	if err != nil {
+		hostname = host
+	}
+
+	var match = func(allowed string) bool {
+		return strings.EqualFold(allowed, host) || strings.EqualFold(allowed, hostname)
+	}
+
+	if p.allowed(p.list(PermissionNet), match) {
+		return nil
+	}
+	return &PermissionError{Kind: PermissionNet, Target: host}
+}
+
+func (p *Permissions) checkPath(kind, path string) error {
+	var abs, err = filepath.Abs(path)
+	if err != nil {
+		return &PermissionError{Kind: kind, Target: path}
+	}
+	abs = resolvePath(abs)
+
+	var inside = func(root string) bool {
+		var rel, err = filepath.Rel(root, abs)
+		return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+	}
+
+	if p.allowed(p.list(kind), inside) {
+		return nil
+	}
+	return &PermissionError{Kind: kind, Target: abs}
+}
+
+func (p *Permissions) allowed(list []string, match func(string) bool) bool {
+	if p == nil {
+		return false
+	}
+	if p.All {
+		return true
+	}
+	for _, allowed := range list {
+		if allowed == AllowAny || match(allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// list returns the allowed values of a kind of permission.
+func (p *Permissions) list(kind string) []string {
+	if p == nil {
+		return nil
+	}
+	switch kind {
+	case PermissionRead:
+		return p.Read
+	case PermissionWrite:
+		return p.Write
+	case PermissionRun:
+		return p.Run
+	case PermissionEnv:
+		return p.Env
+	case PermissionNet:
+		return p.Net
+	}
+	return nil
+}
+
+// resolvePath resolves the symbolic links in the path, so links cannot be used to escape an allowed directory.
+// Paths which do not exist yet are resolved as far as they exist.
+func resolvePath(path string) string {
+	path = filepath.Clean(path)
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+
+	var dir, base = filepath.Split(path)
+	dir = filepath.Clean(dir)
+	if dir == path {
+		return path
+	}
+	return filepath.Join(resolvePath(dir), base)
+}
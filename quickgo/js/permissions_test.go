@@ -0,0 +1,95 @@
+package js_test
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/js"
+)
+
+func TestParsePermissions(t *testing.T) {
+	var perms, err = js.ParsePermissions(`#!/usr/bin/env quickgo
+// A script which builds the project.
+// @permissions allow-read=.,./templates allow-write=./dist
+// @permissions allow-run=git,go allow-env allow-net=api.example.com
+
+"use strict";
+// @permissions allow-all
+function main() {}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if perms.All {
+		t.Error("expected permissions after the header to be ignored")
+	}
+
+	var expected = &js.Permissions{
+		Read:  []string{".", "./templates"},
+		Write: []string{"./dist"},
+		Run:   []string{"git", "go"},
+		Env:   []string{js.AllowAny},
+		Net:   []string{"api.example.com"},
+	}
+	if !slices.Equal(perms.Read, expected.Read) || !slices.Equal(perms.Write, expected.Write) ||
+		!slices.Equal(perms.Run, expected.Run) || !slices.Equal(perms.Env, expected.Env) ||
+		!slices.Equal(perms.Net, expected.Net) {
+		t.Errorf("expected %+v, got %+v", expected, perms)
+	}
+
+	if _, err = js.ParsePermissions("// @permissions allow-everything"); err == nil {
+		t.Error("expected an error for an unknown permission")
+	}
+}
+
+func TestPermissions(t *testing.T) {
+	var (
+		dir   = t.TempDir()
+		perms = (&js.Permissions{
+			Read: []string{"."},
+			Run:  []string{"git"},
+			Net:  []string{"example.com", "localhost:8080"},
+		}).Resolve(dir)
+	)
+
+	var tests = []struct {
+		name  string
+		check error
+		allow bool
+	}{
+		{"read inside", perms.CheckRead(filepath.Join(dir, "a", "b.txt")), true},
+		{"read root", perms.CheckRead(dir), true},
+		{"read parent", perms.CheckRead(filepath.Dir(dir)), false},
+		{"read sibling", perms.CheckRead(dir + "-other"), false},
+		{"write", perms.CheckWrite(filepath.Join(dir, "a.txt")), false},
+		{"run", perms.CheckRun("git"), true},
+		{"run other", perms.CheckRun("/usr/bin/git"), false},
+		{"env", perms.CheckEnv("HOME"), false},
+		{"net host", perms.CheckNet("example.com:443"), true},
+		{"net port", perms.CheckNet("localhost:8080"), true},
+		{"net other port", perms.CheckNet("localhost:9090"), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.allow && test.check != nil {
+				t.Errorf("expected to be allowed, got %v", test.check)
+			}
+			if !test.allow && !errors.Is(test.check, js.ErrPermissionDenied) {
+				t.Errorf("expected %v, got %v", js.ErrPermissionDenied, test.check)
+			}
+		})
+	}
+
+	var none *js.Permissions
+	if err := none.CheckEnv("HOME"); !errors.Is(err, js.ErrPermissionDenied) {
+		t.Errorf("expected nil permissions to deny everything, got %v", err)
+	}
+
+	if err := (&js.Permissions{All: true}).CheckRun("rm"); err != nil {
+		t.Errorf("expected allow-all to allow everything, got %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+// Package metrics implements counters and histograms which can be exposed in the Prometheus text format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// The content type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the histogram buckets for request latencies in seconds.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type (
+	// Metric is a collection of values which can be written in the Prometheus text format.
+	Metric interface {
+		WriteTo(w io.Writer) (int64, error)
+	}
+
+	// Registry is a list of metrics, it serves them over HTTP.
+	// It is safe for concurrent use.
+	Registry struct {
+		mu      sync.RWMutex
+		metrics []Metric
+	}
+
+	// Counter is a value per combination of labels which can only go up.
+	Counter struct {
+		name   string
+		help   string
+		labels []string
+
+		mu     sync.Mutex
+		values map[string]*counterValue
+	}
+
+	// Histogram counts observations per combination of labels in configurable buckets.
+	Histogram struct {
+		name    string
+		help    string
+		labels  []string
+		buckets []float64
+
+		mu     sync.Mutex
+		values map[string]*histogramValue
+	}
+
+	counterValue struct {
+		labels []string
+		value  float64
+	}
+
+	histogramValue struct {
+		labels []string
+		counts []uint64
+		count  uint64
+		sum    float64
+	}
+)
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds metrics to the registry.
+func (r *Registry) Register(metrics ...Metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, metrics...)
+}
+
+// WriteTo writes all metrics in the Prometheus text format.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var total int64
+	for _, m := range r.metrics {
+		var n, err = m.WriteTo(w)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("Cache-Control", "no-store")
+	r.WriteTo(w)
+}
+
+// NewCounter returns a counter with the given label names.
+func NewCounter(name, help string, labels ...string) *Counter {
+	return &Counter{
+		name:   name,
+		help:   help,
+		labels: labels,
+		values: make(map[string]*counterValue),
+	}
+}
+
+// Inc increments the counter for the label values by one.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v to the counter for the label values.
+// It panics if the number of label values does not match the label names, or if v is negative.
+func (c *Counter) Add(v float64, labelValues ...string) {
+	checkLabels(c.name, c.labels, labelValues)
+	if v < 0 {
+		panic(fmt.Sprintf("metrics: counter %s cannot decrease", c.name))
+	}
+
+	var key = strings.Join(labelValues, "\xff")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var value, ok = c.values[key]
+	if !ok {
+		value = &counterValue{labels: labelValues}
+		c.values[key] = value
+	}
+	value.value += v
+}
+
+// Value returns the current value of the counter for the label values.
+func (c *Counter) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if value, ok := c.values[strings.Join(labelValues, "\xff")]; ok {
+		return value.value
+	}
+	return 0
+}
+
+func (c *Counter) WriteTo(w io.Writer) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var b strings.Builder
+	writeHeader(&b, c.name, c.help, "counter")
+	for _, key := range sortedKeys(c.values) {
+		var value = c.values[key]
+		fmt.Fprintf(&b, "%s%s %s\n", c.name, formatLabels(c.labels, value.labels, "", ""), formatFloat(value.value))
+	}
+
+	var n, err = io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// NewHistogram returns a histogram with the given buckets and label names.
+// The buckets must be sorted in increasing order, the +Inf bucket is added automatically.
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return &Histogram{
+		name:    name,
+		help:    help,
+		labels:  labels,
+		buckets: buckets,
+		values:  make(map[string]*histogramValue),
+	}
+}
+
+// Observe adds a single observation to the histogram for the label values.
+// It panics if the number of label values does not match the label names.
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	checkLabels(h.name, h.labels, labelValues)
+
+	var key = strings.Join(labelValues, "\xff")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var value, ok = h.values[key]
+	if !ok {
+		value = &histogramValue{
+			labels: labelValues,
+			counts: make([]uint64, len(h.buckets)),
+		}
+		h.values[key] = value
+	}
+
+	for i, upper := range h.buckets {
+		if v <= upper {
+			value.counts[i]++
+		}
+	}
+	value.count++
+	value.sum += v
+}
+
+func (h *Histogram) WriteTo(w io.Writer) (int64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var b strings.Builder
+	writeHeader(&b, h.name, h.help, "histogram")
+	for _, key := range sortedKeys(h.values) {
+		var value = h.values[key]
+		for i, upper := range h.buckets {
+			fmt.Fprintf(&b, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, value.labels, "le", formatFloat(upper)), value.counts[i])
+		}
+		fmt.Fprintf(&b, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, value.labels, "le", "+Inf"), value.count)
+		fmt.Fprintf(&b, "%s_sum%s %s\n", h.name, formatLabels(h.labels, value.labels, "", ""), formatFloat(value.sum))
+		fmt.Fprintf(&b, "%s_count%s %d\n", h.name, formatLabels(h.labels, value.labels, "", ""), value.count)
+	}
+
+	var n, err = io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func checkLabels(name string, labels, values []string) {
+	if len(labels) != len(values) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", name, len(labels), len(values)))
+	}
+}
+
+func writeHeader(b *strings.Builder, name, help, kind string) {
+	fmt.Fprintf(b, "# HELP %s %s\n", name, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help))
+	fmt.Fprintf(b, "# TYPE %s %s\n", name, kind)
+}
+
+// formatLabels formats the label pairs, optionally with an extra label.
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	if len(names) == 0 && extraName == "" {
+		return ""
+	}
+
+	var escape = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	var pairs = make([]string, 0, len(names)+1)
+	for i, name := range names {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, escape.Replace(values[i])))
+	}
+	if extraName != "" {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extraName, escape.Replace(extraValue)))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	var keys = make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,86 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/metrics"
+)
+
+func TestCounter(t *testing.T) {
+	var c = metrics.NewCounter("test_total", "A test counter.", "template")
+	c.Inc("a")
+	c.Inc("a")
+	c.Add(3, `b"c`)
+
+	if v := c.Value("a"); v != 2 {
+		t.Errorf("expected 2, got %v", v)
+	}
+
+	var b strings.Builder
+	if _, err := c.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	var expected = "# HELP test_total A test counter.\n" +
+		"# TYPE test_total counter\n" +
+		"test_total{template=\"a\"} 2\n" +
+		"test_total{template=\"b\\\"c\"} 3\n"
+	if b.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, b.String())
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	var h = metrics.NewHistogram("test_seconds", "A test histogram.", []float64{0.1, 1})
+	h.Observe(0.05)
+	h.Observe(0.5)
+	h.Observe(2)
+
+	var b strings.Builder
+	if _, err := h.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	var expected = "# HELP test_seconds A test histogram.\n" +
+		"# TYPE test_seconds histogram\n" +
+		"test_seconds_bucket{le=\"0.1\"} 1\n" +
+		"test_seconds_bucket{le=\"1\"} 2\n" +
+		"test_seconds_bucket{le=\"+Inf\"} 3\n" +
+		"test_seconds_sum 2.55\n" +
+		"test_seconds_count 3\n"
+	if b.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, b.String())
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	var (
+		r = metrics.NewRegistry()
+		c = metrics.NewCounter("test_total", "A test counter.")
+	)
+	r.Register(c)
+	c.Inc()
+
+	var rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Header().Get("Content-Type") != metrics.ContentType {
+		t.Errorf("unexpected content type %q", rec.Header().Get("Content-Type"))
+	}
+
+	if !strings.Contains(rec.Body.String(), "test_total 1\n") {
+		t.Errorf("expected counter in output, got\n%s", rec.Body.String())
+	}
+}
+
+func TestLabelMismatch(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for missing label values")
+		}
+	}()
+	metrics.NewCounter("test_total", "A test counter.", "template").Inc()
+}
@@ -0,0 +1,52 @@
+package quickgo
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	OutputTable = "table" // Human readable (colored) text, this is the default.
+	OutputJSON  = "json"  // Indented JSON.
+	OutputYAML  = "yaml"  // YAML.
+)
+
+var ErrOutputFormat = errors.New("unknown output format, expected 'table', 'json' or 'yaml'")
+
+// ValidateOutputFormat returns ErrOutputFormat if the output format is not known.
+func ValidateOutputFormat(format string) error {
+	switch format {
+	case "", OutputTable, OutputJSON, OutputYAML:
+		return nil
+	}
+	return errors.Wrapf(ErrOutputFormat, "format %q", format)
+}
+
+// IsMachineOutput reports whether the output format is meant to be read by other programs.
+func IsMachineOutput(format string) bool {
+	return format == OutputJSON || format == OutputYAML
+}
+
+// WriteOutput writes v to w in the given output format.
+// For the table format, table is called to print v in a human readable way instead.
+func WriteOutput(w io.Writer, format string, v any, table func() error) error {
+	switch format {
+	case "", OutputTable:
+		return table()
+	case OutputJSON:
+		var enc = json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case OutputYAML:
+		var enc = yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	return errors.Wrapf(ErrOutputFormat, "format %q", format)
+}
@@ -0,0 +1,66 @@
+package quickgo_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo"
+)
+
+func TestWriteOutput(t *testing.T) {
+	var summary = &quickgo.ProjectSummary{
+		Name: "test-project",
+		Tags: []string{"go"},
+	}
+
+	var tests = map[string]string{
+		quickgo.OutputJSON: "{\n  \"name\": \"test-project\",\n  \"description\": \"\",\n  \"tags\": [\n    \"go\"\n  ],\n  \"author\": \"\",\n  \"homepage\": \"\",\n  \"minVersion\": \"\",\n  \"icon\": \"\",\n  \"visibility\": \"\"\n}\n",
+		quickgo.OutputYAML: "name: test-project\ndescription: \"\"\ntags:\n  - go\nauthor: \"\"\nhomepage: \"\"\nminVersion: \"\"\nicon: \"\"\nvisibility: \"\"\n",
+	}
+
+	for format, expected := range tests {
+		var b = new(bytes.Buffer)
+		var err = quickgo.WriteOutput(b, format, summary, func() error {
+			t.Errorf("%s: table function should not be called", format)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != expected {
+			t.Errorf("%s: expected\n%s\ngot\n%s", format, expected, b.String())
+		}
+	}
+
+	var called bool
+	if err := quickgo.WriteOutput(nil, quickgo.OutputTable, summary, func() error {
+		called = true
+		return nil
+	}); err != nil || !called {
+		t.Errorf("expected table function to be called, got %v", err)
+	}
+
+	if err := quickgo.ValidateOutputFormat("xml"); !errors.Is(err, quickgo.ErrOutputFormat) {
+		t.Errorf("expected ErrOutputFormat, got %v", err)
+	}
+}
+
+func TestNoColor(t *testing.T) {
+	var noColor = quickgo.NoColor
+	t.Cleanup(func() { quickgo.NoColor = noColor })
+
+	quickgo.NoColor = false
+	if s := quickgo.Craft(quickgo.CMD_Red, "test"); s != quickgo.CMD_Red+"test"+quickgo.CMD_Reset {
+		t.Errorf("expected colored string, got %q", s)
+	}
+
+	quickgo.NoColor = true
+	if s := quickgo.Craft(quickgo.CMD_Red, "test"); s != "test" {
+		t.Errorf("expected plain string, got %q", s)
+	}
+
+	if s := quickgo.BuildColorString(quickgo.CMD_Cyan, quickgo.CMD_Bold, "name"); s != "name" {
+		t.Errorf("expected plain string, got %q", s)
+	}
+}
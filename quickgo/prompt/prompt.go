@@ -0,0 +1,280 @@
+// Package prompt asks the user questions on a terminal.
+//
+// When the prompter is not interactive, for example in CI, answers are looked up by the name of the question instead.
+package prompt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var ErrNoAnswer = errors.New("no answer")
+
+type (
+	// Answers looks up the answer to a question by its name.
+	Answers func(name string) (answer string, ok bool)
+
+	// Question is a question to ask the user.
+	Question struct {
+		Name    string   // The name to look the answer up with, optional.
+		Message string   // The question shown to the user.
+		Default []string // The default answer, used when the answer is empty. Nil if there is no default.
+		Options []string // The options of select and multi-select questions.
+	}
+
+	// Prompter asks questions by writing them to Out and reading the answers from In.
+	//
+	// Answers found with Answers are used without asking the question.
+	// If the prompter is not interactive, the default answer is used when no answer is found.
+	Prompter struct {
+		In          *bufio.Reader
+		Out         io.Writer
+		Interactive bool
+		Answers     Answers
+
+		// HideInput hides the input of the user for passwords, the returned function shows it again.
+		// The input is shown if nil.
+		HideInput func() (show func(), err error)
+	}
+)
+
+// New returns a prompter which reads from in and writes to out.
+func New(in io.Reader, out io.Writer, interactive bool, answers Answers) *Prompter {
+	var r, ok = in.(*bufio.Reader)
+	if !ok {
+		r = bufio.NewReader(in)
+	}
+
+	return &Prompter{
+		In:          r,
+		Out:         out,
+		Interactive: interactive,
+		Answers:     answers,
+	}
+}
+
+// Text asks for a line of text.
+func (p *Prompter) Text(q Question) (string, error) {
+	return p.ask(q, defaultHint(q), func(answer string) (string, error) {
+		return answer, nil
+	})
+}
+
+// Password asks for a line of text without showing it.
+// The default answer is not shown.
+func (p *Prompter) Password(q Question) (string, error) {
+	if _, answered := p.lookup(q); !answered && p.Interactive && p.HideInput != nil {
+		var show, err = p.HideInput()
+		if err == nil {
+			// The newline of the user is hidden as well.
+			defer fmt.Fprintln(p.Out)
+			defer show()
+		}
+	}
+
+	return p.ask(q, "", func(answer string) (string, error) {
+		return answer, nil
+	})
+}
+
+// Confirm asks a yes or no question.
+func (p *Prompter) Confirm(q Question) (bool, error) {
+	var hint = "y/n"
+	if len(q.Default) > 0 {
+		if yes, err := parseBool(q.Default[0]); err == nil && yes {
+			hint = "Y/n"
+		} else if err == nil {
+			hint = "y/N"
+		}
+	}
+
+	var answer, err = p.ask(q, hint, func(answer string) (string, error) {
+		var _, err = parseBool(answer)
+		return answer, err
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return parseBool(answer)
+}
+
+// Select asks to choose one of the options, by its number or value.
+func (p *Prompter) Select(q Question) (string, error) {
+	if len(q.Options) == 0 {
+		return "", errors.Errorf("no options for %q", q.Message)
+	}
+
+	return p.ask(q, defaultHint(q), func(answer string) (string, error) {
+		return q.option(answer)
+	})
+}
+
+// MultiSelect asks to choose any number of the options, separated by commas.
+func (p *Prompter) MultiSelect(q Question) ([]string, error) {
+	if len(q.Options) == 0 {
+		return nil, errors.Errorf("no options for %q", q.Message)
+	}
+
+	var selected []string
+	var _, err = p.ask(q, defaultHint(q), func(answer string) (string, error) {
+		selected = make([]string, 0)
+		for _, part := range strings.Split(answer, ",") {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+
+			var option, err = q.option(part)
+			if err != nil {
+				return "", err
+			}
+
+			if !slices.Contains(selected, option) {
+				selected = append(selected, option)
+			}
+		}
+		return answer, nil
+	})
+	return selected, err
+}
+
+// ask returns the answer to the question, validated and normalized by parse.
+// The question is asked again while the answer is invalid.
+func (p *Prompter) ask(q Question, hint string, parse func(answer string) (string, error)) (string, error) {
+	if answer, ok := p.lookup(q); ok {
+		return parseAnswer(q, answer, parse)
+	}
+
+	if !p.Interactive {
+		if q.Default != nil {
+			return parseAnswer(q, strings.Join(q.Default, ","), parse)
+		}
+		return "", q.noAnswer()
+	}
+
+	for {
+		q.write(p.Out, hint)
+
+		var line, err = p.In.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			if err == io.EOF {
+				return "", q.noAnswer()
+			}
+			return "", errors.Wrapf(err, "failed to read the answer to %q", q.Message)
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" && q.Default != nil {
+			line = strings.Join(q.Default, ",")
+		}
+
+		answer, parseErr := parse(line)
+		if parseErr == nil {
+			return answer, nil
+		}
+
+		fmt.Fprintln(p.Out, parseErr)
+		if err == io.EOF {
+			return "", parseErr
+		}
+	}
+}
+
+// lookup returns the answer found with Answers.
+func (p *Prompter) lookup(q Question) (string, bool) {
+	if p.Answers == nil || q.Name == "" {
+		return "", false
+	}
+	return p.Answers(q.Name)
+}
+
+func parseAnswer(q Question, answer string, parse func(answer string) (string, error)) (string, error) {
+	var parsed, err = parse(answer)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid answer for %q", q.nameOrMessage())
+	}
+	return parsed, nil
+}
+
+// option returns the option with the value, or the number of the option starting at 1.
+func (q Question) option(answer string) (string, error) {
+	if slices.Contains(q.Options, answer) {
+		return answer, nil
+	}
+
+	if i, err := strconv.Atoi(answer); err == nil && i >= 1 && i <= len(q.Options) {
+		return q.Options[i-1], nil
+	}
+
+	return "", errors.Errorf("%q is not one of %s", answer, strings.Join(q.Options, ", "))
+}
+
+func (q Question) write(w io.Writer, hint string) {
+	for i, option := range q.Options {
+		fmt.Fprintf(w, "  %d) %s\n", i+1, option)
+	}
+
+	if hint != "" {
+		fmt.Fprintf(w, "%s [%s]: ", q.Message, hint)
+	} else {
+		fmt.Fprintf(w, "%s: ", q.Message)
+	}
+}
+
+func (q Question) noAnswer() error {
+	if q.Name == "" {
+		return errors.Wrapf(ErrNoAnswer, "%q cannot be asked without a terminal", q.Message)
+	}
+	return errors.Wrapf(ErrNoAnswer, "%q cannot be asked without a terminal, provide %s as an answer", q.Message, q.Name)
+}
+
+func (q Question) nameOrMessage() string {
+	if q.Name != "" {
+		return q.Name
+	}
+	return q.Message
+}
+
+func defaultHint(q Question) string {
+	return strings.Join(q.Default, ",")
+}
+
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "y", "yes":
+		return true, nil
+	case "n", "no":
+		return false, nil
+	}
+
+	var b, err = strconv.ParseBool(s)
+	if err != nil {
+		return false, errors.Errorf("%q is not yes or no", s)
+	}
+	return b, nil
+}
+
+// Stty hides the input of the terminal connected to f with stty,
+// it can be used as the HideInput function of a prompter.
+func Stty(f *os.File) func() (func(), error) {
+	var stty = func(arg string) error {
+		var cmd = exec.Command("stty", arg)
+		cmd.Stdin = f
+		return cmd.Run()
+	}
+
+	return func() (func(), error) {
+		if err := stty("-echo"); err != nil {
+			return nil, errors.Wrap(err, "failed to hide the input")
+		}
+		return func() { stty("echo") }, nil
+	}
+}
@@ -0,0 +1,115 @@
+package prompt_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/prompt"
+)
+
+func TestInteractive(t *testing.T) {
+	var (
+		out = new(strings.Builder)
+		p   = prompt.New(strings.NewReader("my-app\n\nmaybe\nn\nredis\n2\n1, postgres ,1\nsecret\n"), out, true, nil)
+	)
+
+	var hidden bool
+	p.HideInput = func() (func(), error) {
+		hidden = true
+		return func() { hidden = false }, nil
+	}
+
+	name, err := p.Text(prompt.Question{Message: "Name"})
+	if err != nil || name != "my-app" {
+		t.Errorf("expected my-app, got %q (%v)", name, err)
+	}
+
+	license, err := p.Text(prompt.Question{Message: "License", Default: []string{"MIT"}})
+	if err != nil || license != "MIT" {
+		t.Errorf("expected the default for an empty answer, got %q (%v)", license, err)
+	}
+
+	// An invalid answer is asked again.
+	ok, err := p.Confirm(prompt.Question{Message: "Continue", Default: []string{"yes"}})
+	if err != nil || ok {
+		t.Errorf("expected no, got %v (%v)", ok, err)
+	}
+
+	db, err := p.Select(prompt.Question{Message: "Database", Options: []string{"sqlite", "postgres"}})
+	if err != nil || db != "postgres" {
+		t.Errorf("expected the option by its number after an invalid option, got %q (%v)", db, err)
+	}
+
+	features, err := p.MultiSelect(prompt.Question{Message: "Features", Options: []string{"auth", "postgres", "docker"}})
+	if err != nil || !slices.Equal(features, []string{"auth", "postgres"}) {
+		t.Errorf("expected auth and postgres, got %v (%v)", features, err)
+	}
+
+	password, err := p.Password(prompt.Question{Message: "Password"})
+	if err != nil || password != "secret" || hidden {
+		t.Errorf("expected the password with the input shown again, got %q (%v)", password, err)
+	}
+
+	for _, expected := range []string{"Name: ", "License [MIT]: ", "Continue [Y/n]: ", `"maybe" is not yes or no`, "  2) postgres\n"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %q in the output:\n%s", expected, out)
+		}
+	}
+
+	if _, err = p.Text(prompt.Question{Message: "More"}); !errors.Is(err, prompt.ErrNoAnswer) {
+		t.Errorf("expected %v at the end of the input, got %v", prompt.ErrNoAnswer, err)
+	}
+}
+
+func TestNonInteractive(t *testing.T) {
+	var answers = map[string]string{
+		"name":     "from-args",
+		"ok":       "true",
+		"db":       "2",
+		"features": "docker,auth",
+		"password": "secret",
+		"invalid":  "mysql",
+	}
+
+	var p = prompt.New(strings.NewReader("not read\n"), new(strings.Builder), false, func(name string) (string, bool) {
+		var answer, ok = answers[name]
+		return answer, ok
+	})
+
+	var options = []string{"sqlite", "postgres"}
+
+	if name, err := p.Text(prompt.Question{Name: "name", Message: "Name", Default: []string{"default"}}); err != nil || name != "from-args" {
+		t.Errorf("expected the answer to be used, got %q (%v)", name, err)
+	}
+
+	if license, err := p.Text(prompt.Question{Name: "license", Message: "License", Default: []string{"MIT"}}); err != nil || license != "MIT" {
+		t.Errorf("expected the default, got %q (%v)", license, err)
+	}
+
+	if ok, err := p.Confirm(prompt.Question{Name: "ok", Message: "Continue"}); err != nil || !ok {
+		t.Errorf("expected yes, got %v (%v)", ok, err)
+	}
+
+	if db, err := p.Select(prompt.Question{Name: "db", Message: "Database", Options: options}); err != nil || db != "postgres" {
+		t.Errorf("expected postgres, got %q (%v)", db, err)
+	}
+
+	if features, err := p.MultiSelect(prompt.Question{Name: "features", Message: "Features", Options: []string{"auth", "docker"}}); err != nil || !slices.Equal(features, []string{"docker", "auth"}) {
+		t.Errorf("expected docker and auth, got %v (%v)", features, err)
+	}
+
+	if password, err := p.Password(prompt.Question{Name: "password", Message: "Password"}); err != nil || password != "secret" {
+		t.Errorf("expected the password, got %q (%v)", password, err)
+	}
+
+	if _, err := p.Select(prompt.Question{Name: "invalid", Message: "Database", Options: options}); err == nil || !strings.Contains(err.Error(), "invalid answer for \"invalid\"") {
+		t.Errorf("expected an error for an invalid answer, got %v", err)
+	}
+
+	var _, err = p.Text(prompt.Question{Name: "missing", Message: "Missing"})
+	if !errors.Is(err, prompt.ErrNoAnswer) || !strings.Contains(err.Error(), "provide missing as an answer") {
+		t.Errorf("expected %v, got %v", prompt.ErrNoAnswer, err)
+	}
+}
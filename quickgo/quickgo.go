@@ -3,23 +3,29 @@ package quickgo
 import (
 	"archive/zip"
 	"bytes"
-	"fmt"
 	"io"
 	"io/fs"
 	"maps"
 	"os"
-	"os/signal"
 	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"text/template"
 
 	"github.com/Nigel2392/goldcrest"
+	"github.com/Nigel2392/quickgo/v2/quickgo/auth"
+	"github.com/Nigel2392/quickgo/v2/quickgo/command"
 	"github.com/Nigel2392/quickgo/v2/quickgo/config"
 	"github.com/Nigel2392/quickgo/v2/quickgo/logger"
 	"github.com/Nigel2392/quickgo/v2/quickgo/quickfs"
+	"github.com/Nigel2392/quickgo/v2/quickgo/search"
+	"github.com/Nigel2392/quickgo/v2/quickgo/store"
 	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
 )
 
 var (
@@ -41,8 +47,9 @@ func (w *ansiStrippedWriter) Write(p []byte) (n int, err error) {
 
 // Output the log file to the given writer.
 // If the writer is nil, the log file will be written to the default location.
+// The returned writer must be closed to close the log file.
 // This function may panic if the log file cannot be opened.
-func Logfile(output io.Writer) io.Writer {
+func Logfile(output io.Writer) io.WriteCloser {
 	var (
 		logPath = GetQuickGoPath(config.QUICKGO_LOG_NAME)
 		err     error
@@ -61,32 +68,42 @@ func Logfile(output io.Writer) io.Writer {
 		writer = &ansiStrippedWriter{file}
 	}
 
-	go func() {
-		var c = make(chan os.Signal, 1)
-		signal.Notify(c, os.Interrupt)
-		<-c
+	return &logfileWriter{
+		Writer: writer,
+		file:   file,
+	}
+}
 
-		logger.Infof("Closing log file %s", logPath)
-		err = file.Close()
-		if err != nil {
-			fmt.Printf("Failed to close log file %s\n", logPath)
-			os.Exit(1)
-		} else {
-			os.Exit(0)
-		}
-	}()
+// logfileWriter writes to the log file and optionally another output.
+type logfileWriter struct {
+	io.Writer
+	file *os.File
+}
 
-	return writer
+func (w *logfileWriter) Close() error {
+	logger.Debugf("Closing log file %s", w.file.Name())
+	return w.file.Close()
 }
 
 type (
 	App struct {
-		Config        *config.QuickGo `yaml:"config"`        // The configuration for QuickGo.
-		ProjectConfig *config.Project `yaml:"projectConfig"` // The configuration for the project.
-		Patterns      []string        `yaml:"patterns"`      // The patterns for the templates.
-		AppFS         fs.FS           `yaml:"-"`             // The file system for the app, resides in the userprofile home directory.
-		ProjectFS     fs.FS           `yaml:"-"`             // The file system for the project, resides in the project (working) directory.
-		logfile       io.Writer       `yaml:"-"`             // The log file.
+		Config          *config.QuickGo     `yaml:"config"`        // The configuration for QuickGo.
+		ProjectConfig   *config.Project     `yaml:"projectConfig"` // The configuration for the project.
+		Patterns        []string            `yaml:"patterns"`      // The patterns for the templates.
+		TemplateFS      fs.FS               `yaml:"-"`             // The templates and static files of the web UI, the embedded files if nil.
+		AppFS           fs.FS               `yaml:"-"`             // The file system for the app, resides in the userprofile home directory.
+		ProjectFS       fs.FS               `yaml:"-"`             // The file system for the project, resides in the project (working) directory.
+		Store           store.TemplateStore `yaml:"-"`             // The storage backend for saved project templates.
+		Auth            auth.Authenticator  `yaml:"-"`             // Authenticates requests to the server, nil if authentication is not configured.
+		SearchIndexPath string              `yaml:"-"`             // The file the search index is persisted to, the index is only kept in memory if empty.
+		AllowAll        bool                `yaml:"-"`             // Allow JS commands to do everything, regardless of their permissions.
+		logfile         io.Writer           `yaml:"-"`             // The log file.
+		searchIndex     *search.Index       `yaml:"-"`             // The search index, loaded on first use.
+		searchMu        sync.Mutex          `yaml:"-"`             // Guards the search index.
+		shuttingDown    atomic.Bool         `yaml:"-"`             // Set when the server is shutting down, the app is then no longer ready.
+		projects        projectCache        `yaml:"-"`             // Stored projects kept in memory for the server.
+		pages           pageCache           `yaml:"-"`             // Parsed page templates of the web UI.
+		metrics         *serverMetrics      `yaml:"-"`             // Metrics of the server, created with the HTTP handler.
 	}
 )
 
@@ -125,6 +142,8 @@ func LoadApp() (*App, error) {
 		Patterns: []string{
 			"_templates/base.tmpl",
 			"_templates/parent_url.tmpl",
+			"_templates/search_form.tmpl",
+			"_templates/code_lines.tmpl",
 		},
 	}
 
@@ -158,6 +177,39 @@ func LoadApp() (*App, error) {
 
 	app.Config = cfg
 
+	// Open the storage backend for the saved project templates.
+	app.Store, err = store.Open(cfg.Store, projectDir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open template store %q", cfg.Store)
+	}
+
+	app.SearchIndexPath = GetQuickGoPath(config.SEARCH_INDEX_NAME)
+
+	// Override the templates and static files of the web UI with the theme.
+	if cfg.Theme != "" {
+		var dir = cfg.Theme
+		if !filepath.IsAbs(dir) {
+			dir = GetQuickGoPath(dir)
+		}
+
+		if app.TemplateFS, err = ThemeFS(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	// Run the JS steps of project commands with this app.
+	command.RunJS = app.ExecJSStep
+
+	// Set up authentication for the server.
+	if len(cfg.PublishTokens) > 0 {
+		logger.Warn("publishTokens is deprecated, use auth.tokens with the publish permission instead")
+	}
+
+	app.Auth, err = NewAuthenticator(cfg.AuthConfig())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to set up authentication")
+	}
+
 	for _, hook := range goldcrest.Get[AppHook](HookQuickGoLoaded) {
 		if err = hook(app); err != nil {
 			return nil, err
@@ -211,8 +263,7 @@ func (a *App) NewProject(conf SimpleProject) (proj *config.Project, err error) {
 		}
 	}
 
-	var dirPath = GetProjectDirectoryPath(proj.Name, true)
-	if _, err = os.Stat(dirPath); err == nil {
+	if _, err = a.Store.Stat(proj.Name); err == nil {
 		return nil, config.ErrProjectExists
 	}
 
@@ -274,6 +325,76 @@ func (a *App) WriteExampleProjectConfig(directory string) (err error) {
 	return config.WriteYaml(example, filepath.Join(directory, config.PROJECT_CONFIG_NAME))
 }
 
+// RenderedFile is a project file or directory after its path and content have been rendered.
+type RenderedFile struct {
+	// The file or directory in the project.
+	Source quickfs.FileLike
+
+	// The rendered path, slash separated and relative to the project directory.
+	Path string
+
+	// The rendered content of the file, nil for directories.
+	Content []byte
+}
+
+// Size returns the size of the rendered content in bytes.
+func (f *RenderedFile) Size() int64 {
+	return int64(len(f.Content))
+}
+
+// IsText reports whether the rendered content is valid utf-8 text.
+func (f *RenderedFile) IsText() bool {
+	return !f.Source.IsDir() && quickfs.IsText(f.Content)
+}
+
+// RenderProject renders the paths and contents of all project files which are not excluded,
+// fn is called for every directory and file in the order the project tree is traversed.
+//
+// The project files are read while rendering, so a project can only be rendered once.
+// If raw is true, the file contents are not executed as templates.
+func (a *App) RenderProject(proj *config.Project, raw bool, fn func(rf *RenderedFile) error) error {
+	if err := CheckMinVersion(proj); err != nil {
+		return err
+	}
+
+	var _, err = proj.Root.Traverse(func(fl quickfs.FileLike) (cancel bool, err error) {
+		if proj.IsExcluded(fl) {
+			logger.Debugf("Excluded %s", fl.GetPath())
+			return false, nil
+		}
+
+		var p = filepath.ToSlash(fl.GetPath())
+		var b = new(bytes.Buffer)
+		if err = a.executeProjectTemplate(proj, b, p); err != nil {
+			return true, errors.Wrapf(err, "failed to execute template for filename %s", p)
+		}
+
+		var rendered = &RenderedFile{
+			Source: fl,
+			Path:   path.Clean(filepath.ToSlash(b.String())),
+		}
+
+		if rendered.Path == ".." || strings.HasPrefix(rendered.Path, "../") || path.IsAbs(rendered.Path) {
+			return true, errors.Errorf("rendered filename %s of %s is outside of the project directory", rendered.Path, p)
+		}
+
+		if f, ok := fl.(*quickfs.FSFile); ok {
+			var content = new(bytes.Buffer)
+			if err = a.CopyFileContent(proj, content, f, raw); err != nil {
+				return true, errors.Wrapf(err, "failed to render file content of %s", p)
+			}
+			rendered.Content = content.Bytes()
+		}
+
+		if err = fn(rendered); err != nil {
+			return true, err
+		}
+
+		return false, nil
+	})
+	return err
+}
+
 func (a *App) WriteProject(proj *config.Project, directory string, raw bool) error {
 
 	var (
@@ -333,63 +454,40 @@ func (a *App) WriteProject(proj *config.Project, directory string, raw bool) err
 
 	logger.Infof("Copying project files to %s", projectDir)
 
-	// Loop over all files in the project.
-	// This gets recursively called by subdirectories.
-	_, err = proj.Root.Traverse(func(fl quickfs.FileLike) (cancel bool, err error) {
-		var p = fl.GetPath()
-		var b = new(bytes.Buffer)
+	// Render all project files and write them to the project directory.
+	err = a.RenderProject(proj, raw, func(rf *RenderedFile) error {
+		var path = filepath.Join(projectDir, filepath.FromSlash(rf.Path))
 
-		err = a.executeProjectTemplate(
-			proj, b, p,
-		)
-		if err != nil {
-			return true, errors.Wrapf(err, "failed to execute template for filename %s", p)
+		if rf.Source.IsDir() {
+			// Create a new subdirectory inside of the project directory.
+			if err := os.MkdirAll(path, os.ModePerm); err != nil {
+				return errors.Wrapf(err, "failed to create directory %s", path)
+			}
+			logger.Debugf("Copied %s to %s", rf.Source.GetPath(), rf.Path)
+			return nil
 		}
 
-		var path = filepath.Join(projectDir, b.String())
-
-		if proj.IsExcluded(fl) {
-			logger.Debugf("Excluded %s", fl.GetPath())
-			return false, nil
+		var dir = filepath.Dir(path)
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return errors.Wrapf(err, "failed to create directory %s", dir)
 		}
 
-		switch f := fl.(type) {
-		case *quickfs.FSFile:
-			// Copy the file content to the new file.
-			// Replace any template variables.
-			var dir = filepath.Dir(path)
-			if err = os.MkdirAll(dir, os.ModePerm); err != nil {
-				return true, errors.Wrapf(err, "failed to create directory %s", dir)
-			}
-
-			osFile, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, os.ModePerm)
-			if err != nil && os.IsExist(err) {
-				logger.Infof("Skipping, file %s already exists", path)
-				return false, nil
-			} else if err != nil {
-				return true, errors.Wrapf(err, "failed to create file %s", path)
-			}
-			defer osFile.Close()
-
-			if err = a.CopyFileContent(proj, osFile, f, raw); err != nil {
-				return true, errors.Wrapf(err, "failed to copy file content to %s", path)
-			}
-
-		case *quickfs.FSDirectory:
-			// Create a new subdirectory inside of the project directory.
-			if err = os.MkdirAll(path, os.ModePerm); err != nil {
-				return true, errors.Wrapf(err, "failed to create directory %s", path)
-			}
+		osFile, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, os.ModePerm)
+		if err != nil && os.IsExist(err) {
+			logger.Infof("Skipping, file %s already exists", path)
+			return nil
+		} else if err != nil {
+			return errors.Wrapf(err, "failed to create file %s", path)
 		}
+		defer osFile.Close()
 
-		rel, err := filepath.Rel(projectDir, path)
+		if _, err = osFile.Write(rf.Content); err != nil {
+			return errors.Wrapf(err, "failed to copy file content to %s", path)
+		}
 
-		logger.Debugf("Copied %s to %s",
-			fl.GetPath(),
-			rel,
-		)
+		logger.Debugf("Copied %s to %s", rf.Source.GetPath(), rf.Path)
 
-		return false, nil
+		return nil
 	})
 
 	if err != nil {
@@ -423,16 +521,12 @@ func (a *App) WriteProject(proj *config.Project, directory string, raw bool) err
 func (a *App) WriteProjectConfig(proj *config.Project) error {
 	var (
 		err     error
-		file    *os.File
-		dirPath = GetProjectDirectoryPath(proj.Name, true)
+		cfg     []byte
+		archive = new(bytes.Buffer)
+		zf      = zip.NewWriter(archive)
 	)
 
-	var path = filepath.Join(dirPath, config.PROJECT_CONFIG_NAME)
-	if err = os.MkdirAll(dirPath, os.ModePerm); err != nil {
-		return err
-	}
-
-	logger.Infof("Writing project config to %s", path)
+	logger.Infof("Writing project config for '%s'", proj.Name)
 
 	for _, hook := range goldcrest.Get[ProjectHook](HookProjectBeforeSave) {
 		if err = hook(a, proj); err != nil {
@@ -440,22 +534,11 @@ func (a *App) WriteProjectConfig(proj *config.Project) error {
 		}
 	}
 
-	err = config.WriteYaml(proj, path)
-	if err != nil {
-		return err
-	}
-
-	var zipPath = filepath.Join(dirPath, config.PROJECT_ZIP_NAME)
-
-	if file, err = os.Create(zipPath); err != nil {
-		return err
+	if cfg, err = yaml.Marshal(proj); err != nil {
+		return errors.Wrapf(err, "failed to marshal project config for '%s'", proj.Name)
 	}
-	defer file.Close()
-
-	var zf = zip.NewWriter(file)
-	defer zf.Close()
 
-	logger.Infof("Writing project files to %s", zipPath)
+	logger.Infof("Writing project files for '%s'", proj.Name)
 
 	_, err = proj.Root.Traverse(func(fl quickfs.FileLike) (cancel bool, err error) {
 		var p = fl.GetPath()
@@ -485,12 +568,20 @@ func (a *App) WriteProjectConfig(proj *config.Project) error {
 		return false, nil
 	})
 
+	if err == nil {
+		err = zf.Close()
+	}
+
 	if err != nil {
-		logger.Errorf("Failed to write project to %s: %s", zipPath, err)
+		logger.Errorf("Failed to write project files for '%s': %s", proj.Name, err)
 		return err
 	}
 
-	logger.Infof("Finished writing project to %s", zipPath)
+	if err = a.Store.Put(proj.Name, cfg, archive); err != nil {
+		return errors.Wrapf(err, "failed to store project '%s'", proj.Name)
+	}
+
+	logger.Infof("Finished writing project '%s'", proj.Name)
 
 	for _, hook := range goldcrest.Get[ProjectHook](HookProjectAfterSave) {
 		if err = hook(a, proj); err != nil {
@@ -501,62 +592,154 @@ func (a *App) WriteProjectConfig(proj *config.Project) error {
 	return err
 }
 
-func (a *App) ReadProjectConfig(name string) (proj *config.Project, closeFiles func(), err error) {
+// ImportProject validates a project configuration and its zip archive and writes them into the store.
+//
+// The archive is rejected if any of its entries escape the project root,
+// if any of its entries would have been excluded by the project configuration,
+// or if its files are larger than store.MaxExportSize together.
+func (a *App) ImportProject(name string, cfg []byte, archive []byte) (*config.Project, error) {
+	var proj, err = config.ReadYaml[config.Project](
+		bytes.NewReader(cfg),
+	)
+	if err != nil {
+		return nil, errors.Wrap(config.ErrProjectInvalid, err.Error())
+	}
 
-	if name == "" || name == "." || strings.ContainsAny(name, `/\`) {
-		return nil, nil, config.ErrProjectName
+	if err = proj.Validate(); err != nil {
+		return nil, err
 	}
 
-	var (
-		file *os.File
-		// dirPath    = getProjectFilePath(name, false)
-		// absDirPath = getProjectFilePath(name, true)
-		absDirPath = GetProjectDirectoryPath(name, true)
-	)
-	proj, err = config.LoadYaml[config.Project](
-		path.Join(absDirPath, config.PROJECT_CONFIG_NAME),
-	)
+	if proj.Name != name {
+		return nil, errors.Wrapf(
+			config.ErrProjectInvalid,
+			"project name '%s' does not match '%s'", proj.Name, name,
+		)
+	}
+
+	zf, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
 	if err != nil {
-		return nil, nil, errors.Wrapf(err, "failed to load YAML for project config %s", name)
+		return nil, errors.Wrapf(config.ErrProjectInvalid, "failed to read project archive: %s", err)
 	}
 
-	for _, hook := range goldcrest.Get[ProjectWithDirHook](HookProjectBeforeLoad) {
-		if err = hook(a, proj, absDirPath); err != nil {
-			return nil, closeFiles, err
+	var size uint64
+	for _, f := range zf.File {
+		size += f.UncompressedSize64
+	}
+
+	if size > uint64(store.MaxExportSize) {
+		return nil, errors.Wrapf(store.ErrExportTooLarge, "the files of project '%s' are larger than %d bytes", name, store.MaxExportSize)
+	}
+
+	for _, f := range zf.File {
+		var p = strings.TrimSuffix(f.Name, "/")
+		if p == "." || p == "" {
+			continue
+		}
+
+		if path.IsAbs(p) || strings.Contains(p, "\\") || slices.Contains(strings.Split(p, "/"), "..") {
+			return nil, errors.Wrapf(config.ErrProjectInvalid, "invalid path in project archive: '%s'", f.Name)
+		}
+
+		// Saving a project skips excluded directories entirely,
+		// each parent directory of the entry must be checked as well.
+		var fileLikes = make([]quickfs.FileLike, 0)
+		for dir := path.Dir(p); dir != "."; dir = path.Dir(dir) {
+			fileLikes = append(fileLikes, &quickfs.FSDirectory{Name: path.Base(dir), Path: dir})
+		}
+
+		if f.FileInfo().IsDir() {
+			fileLikes = append(fileLikes, &quickfs.FSDirectory{Name: path.Base(p), Path: p})
+		} else {
+			fileLikes = append(fileLikes, &quickfs.FSFile{Name: path.Base(p), Path: p})
+		}
+
+		for _, fl := range fileLikes {
+			if proj.IsExcluded(fl) {
+				return nil, errors.Wrapf(config.ErrProjectInvalid, "project archive contains excluded path: '%s'", f.Name)
+			}
 		}
 	}
 
-	proj.Root = quickfs.NewFSDirectory(
-		proj.Name,
-		".",
-		nil,
-	)
-	proj.Root.IsExcluded = proj.IsExcluded
+	if err = a.Store.Put(name, cfg, bytes.NewReader(archive)); err != nil {
+		return nil, errors.Wrapf(err, "failed to store project '%s'", name)
+	}
 
-	file, err = os.Open(
-		path.Join(absDirPath, config.PROJECT_ZIP_NAME),
-	)
+	logger.Infof("Imported project '%s'", name)
+
+	for _, hook := range goldcrest.Get[ProjectHook](HookProjectAfterSave) {
+		if err = hook(a, proj); err != nil {
+			return nil, err
+		}
+	}
+
+	return proj, nil
+}
+
+func (a *App) ReadProjectConfig(name string) (proj *config.Project, closeFiles func(), err error) {
+	return a.readProject(a.Store, name)
+}
+
+// readProject reads the named project and its files from the given store.
+// The returned function must be called to close the project files.
+func (a *App) readProject(st store.TemplateStore, name string) (proj *config.Project, closeFiles func(), err error) {
+
+	if name == "" || name == "." || strings.ContainsAny(name, `/\`) {
+		return nil, nil, config.ErrProjectName
+	}
+
+	var tpl *store.Template
+	if tpl, err = st.Get(name); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to get project %s", name)
+	}
+
+	zf, err := zip.NewReader(tpl.Archive, tpl.Size)
 	if err != nil {
-		return nil, nil, errors.Wrapf(err, "failed to open zip file for project %s", name)
+		tpl.Close()
+		return nil, nil, errors.Wrapf(err, "failed to read zip file for project %s", name)
 	}
 
-	stat, err := file.Stat()
+	proj, closeProject, err := a.loadProject(name, tpl.Config, zf)
 	if err != nil {
-		return nil, nil, errors.Wrapf(err, "failed to get file info for %s", file.Name())
+		tpl.Close()
+		return nil, nil, err
 	}
 
-	zf, err := zip.NewReader(file, stat.Size())
+	return proj, func() {
+		closeProject()
+		tpl.Close()
+	}, nil
+}
+
+// loadProject parses the project configuration and builds the project tree from the zipped files.
+// The returned function must be called to close the project files.
+func (a *App) loadProject(name string, cfg []byte, zf *zip.Reader) (proj *config.Project, closeFiles func(), err error) {
+	proj, err = config.ReadYaml[config.Project](
+		bytes.NewReader(cfg),
+	)
 	if err != nil {
-		return nil, nil, errors.Wrapf(err, "failed to read zip file %s", file.Name())
+		return nil, nil, errors.Wrapf(err, "failed to load YAML for project config %s", name)
 	}
 
-	var zipFiles = make([]io.ReadCloser, 0, len(zf.File))
+	var zipFiles = make([]io.ReadCloser, 0)
 	closeFiles = func() {
 		for _, f := range zipFiles {
 			f.Close()
 		}
 	}
 
+	for _, hook := range goldcrest.Get[ProjectWithDirHook](HookProjectBeforeLoad) {
+		if err = hook(a, proj, GetProjectDirectoryPath(name, true)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	proj.Root = quickfs.NewFSDirectory(
+		proj.Name,
+		".",
+		nil,
+	)
+	proj.Root.IsExcluded = proj.IsExcluded
+
 	for _, f := range zf.File {
 		var (
 			fInfo = f.FileInfo()
@@ -573,7 +756,8 @@ func (a *App) ReadProjectConfig(name string) (proj *config.Project, closeFiles f
 
 			zipF, err := f.Open()
 			if err != nil {
-				return nil, closeFiles, err
+				closeFiles()
+				return nil, nil, err
 			}
 
 			zipFiles = append(zipFiles, zipF)
@@ -585,14 +769,15 @@ func (a *App) ReadProjectConfig(name string) (proj *config.Project, closeFiles f
 
 	for _, hook := range goldcrest.Get[ProjectHook](HookQuickGoLoaded) {
 		if err = hook(a, proj); err != nil {
-			return nil, closeFiles, err
+			closeFiles()
+			return nil, nil, err
 		}
 	}
 
 	return proj, closeFiles, nil
 }
 
-func (a *App) CopyFileContent(proj *config.Project, file *os.File, f *quickfs.FSFile, raw bool) error {
+func (a *App) CopyFileContent(proj *config.Project, file io.Writer, f *quickfs.FSFile, raw bool) error {
 
 	if raw {
 		_, err := io.Copy(file, f)
@@ -624,26 +809,23 @@ func (a *App) CopyFileContent(proj *config.Project, file *os.File, f *quickfs.FS
 }
 
 func (a *App) ListProjectObjects() ([]*config.Project, error) {
-	var (
-		projects = make([]*config.Project, 0)
-		dirPath  = GetProjectDirectoryPath("", true)
-	)
+	return a.listProjects(a.Store)
+}
 
-	dir, err := os.ReadDir(dirPath)
+// listProjects reads the configurations of all projects in the given store.
+func (a *App) listProjects(st store.TemplateStore) ([]*config.Project, error) {
+	var templates, err = st.List()
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to read directory %s", dirPath)
+		return nil, errors.Wrap(err, "failed to list stored projects")
 	}
 
-	for _, d := range dir {
-		if !d.IsDir() {
-			continue
-		}
-
-		var path = filepath.Join(dirPath, d.Name())
-		var configName = filepath.Join(path, config.PROJECT_CONFIG_NAME)
-		proj, err := config.LoadYaml[config.Project](configName)
-		if err != nil && !os.IsNotExist(err) {
-			return nil, errors.Wrapf(err, "failed to load project config %s", configName)
+	var projects = make([]*config.Project, 0, len(templates))
+	for _, tpl := range templates {
+		proj, err := config.ReadYaml[config.Project](
+			bytes.NewReader(tpl.Config),
+		)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load project config %s", tpl.Name)
 		}
 
 		projects = append(projects, proj)
@@ -0,0 +1,376 @@
+package quickgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/config"
+	"github.com/Nigel2392/quickgo/v2/quickgo/logger"
+	"github.com/Nigel2392/quickgo/v2/quickgo/quickfs"
+	"github.com/Nigel2392/quickgo/v2/quickgo/search"
+	"github.com/Nigel2392/quickgo/v2/quickgo/store"
+	"github.com/pkg/errors"
+)
+
+const (
+	// The path prefix for the JSON API.
+	APIPathPrefix = "/api/v1/"
+
+	// The maximum size of a render request body.
+	maxRenderRequestSize = 1 << 20
+)
+
+type (
+	// APIProject is the JSON representation of a stored project.
+	APIProject struct {
+		Name    string    `json:"name"`
+		Size    int64     `json:"size"`
+		ModTime time.Time `json:"modTime"`
+
+		// The project configuration.
+		// Only included when requesting a single project.
+		Config *config.Project `json:"config,omitempty"`
+	}
+
+	// APIProjectList is the response body when listing projects.
+	APIProjectList struct {
+		Projects []*APIProject `json:"projects"`
+	}
+
+	// APIFile is a file or directory in a project's file tree.
+	APIFile struct {
+		Name     string     `json:"name"`
+		Path     string     `json:"path"`
+		IsDir    bool       `json:"isDir"`
+		Size     int64      `json:"size"`
+		Children []*APIFile `json:"children,omitempty"`
+	}
+
+	// APIRenderRequest is the request body for rendering a file.
+	APIRenderRequest struct {
+		// Extra context for the project templates.
+		// This is merged into the project's own context.
+		Context map[string]any `json:"context"`
+	}
+
+	// APISearchResponse is the response body of a search query.
+	APISearchResponse struct {
+		Query   string           `json:"query"`
+		Results []*search.Result `json:"results"`
+	}
+
+	// APIRenderResponse is the response body of a rendered file.
+	APIRenderResponse struct {
+		// The path of the file in the project.
+		Path string `json:"path"`
+
+		// The rendered path of the file.
+		RenderedPath string `json:"renderedPath"`
+
+		// The rendered content of the file.
+		Content string `json:"content"`
+	}
+)
+
+// serveAPI serves the JSON API for integrating with other tools.
+//
+//	GET      projects                         -> list all projects with metadata
+//	GET      projects/<name>                  -> metadata and configuration of a project
+//	GET      projects/<name>/config           -> configuration of a project
+//	GET      projects/<name>/tree             -> file tree of a project
+//	GET      projects/<name>/files/<path>     -> raw content of a file
+//	GET/POST projects/<name>/render/<path>    -> rendered content of a file
+//	GET      search?q=<query>                 -> files matching the query
+func (a *App) serveAPI(w http.ResponseWriter, r *http.Request) {
+	var pathParts = strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(pathParts) == 1 && pathParts[0] == "search" && (r.Method == http.MethodGet || r.Method == http.MethodHead) {
+		a.serveAPISearch(w, r)
+		return
+	}
+
+	if pathParts[0] != "projects" {
+		writeJSONError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	var isRender = len(pathParts) > 2 && pathParts[2] == "render"
+	if r.Method != http.MethodGet && r.Method != http.MethodHead && !(isRender && r.Method == http.MethodPost) {
+		writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	switch {
+	case len(pathParts) == 1:
+		a.serveAPIProjects(w, r)
+	case len(pathParts) == 2:
+		a.serveAPIProject(w, r, pathParts[1])
+	case len(pathParts) == 3 && pathParts[2] == "config":
+		a.serveAPIConfig(w, r, pathParts[1])
+	case len(pathParts) == 3 && pathParts[2] == "tree":
+		a.serveAPITree(w, r, pathParts[1])
+	case len(pathParts) > 3 && pathParts[2] == "files":
+		a.serveAPIFile(w, r, pathParts[1], pathParts[3:])
+	case len(pathParts) > 3 && isRender:
+		a.serveAPIRender(w, r, pathParts[1], pathParts[3:])
+	default:
+		writeJSONError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+func (a *App) serveAPIProjects(w http.ResponseWriter, r *http.Request) {
+	var templates, err = a.requestStore(r).List()
+	if err != nil {
+		logger.Errorf("Failed to list projects for API: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to list projects")
+		return
+	}
+
+	var list = &APIProjectList{
+		Projects: make([]*APIProject, 0, len(templates)),
+	}
+
+	for _, tpl := range templates {
+		list.Projects = append(list.Projects, &APIProject{
+			Name:    tpl.Name,
+			Size:    tpl.Size,
+			ModTime: tpl.ModTime,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (a *App) serveAPIProject(w http.ResponseWriter, r *http.Request, name string) {
+	var st = a.requestStore(r)
+	var meta, err = st.Stat(name)
+	if err != nil {
+		writeAPIProjectError(w, name, err)
+		return
+	}
+
+	proj, ok := a.readAPIConfig(w, st, name)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &APIProject{
+		Name:    meta.Name,
+		Size:    meta.Size,
+		ModTime: meta.ModTime,
+		Config:  proj,
+	})
+}
+
+func (a *App) serveAPIConfig(w http.ResponseWriter, r *http.Request, name string) {
+	var proj, ok = a.readAPIConfig(w, a.requestStore(r), name)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, proj)
+}
+
+func (a *App) serveAPITree(w http.ResponseWriter, r *http.Request, name string) {
+	var proj, closeFiles, err = a.requestProject(r, name)
+	if err != nil {
+		writeAPIProjectError(w, name, err)
+		return
+	}
+	defer closeFiles()
+
+	writeJSON(w, http.StatusOK, newAPIFile(proj.Root))
+}
+
+func (a *App) serveAPIFile(w http.ResponseWriter, r *http.Request, name string, filePath []string) {
+	var proj, closeFiles, err = a.requestProject(r, name)
+	if err != nil {
+		writeAPIProjectError(w, name, err)
+		return
+	}
+	defer closeFiles()
+
+	file, ok := findAPIFile(w, proj, filePath)
+	if !ok {
+		return
+	}
+
+	var b = new(bytes.Buffer)
+	if _, err = io.Copy(b, file); err != nil {
+		logger.Errorf("Failed to read file '%s' in project '%s': %v", file.GetPath(), name, err)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to read file")
+		return
+	}
+
+	if quickfs.IsText(b.Bytes()) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if _, err = io.Copy(w, b); err != nil {
+		logger.Errorf("Failed to write file '%s' in project '%s': %v", file.GetPath(), name, err)
+	}
+}
+
+func (a *App) serveAPIRender(w http.ResponseWriter, r *http.Request, name string, filePath []string) {
+	var request = &APIRenderRequest{
+		Context: make(map[string]any),
+	}
+
+	// Context can be provided as a JSON body, or as query parameters.
+	if r.Method == http.MethodPost {
+		var decoder = json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRenderRequestSize))
+		if err := decoder.Decode(request); err != nil && err != io.EOF {
+			writeJSONError(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+			return
+		}
+	}
+
+	for k, v := range r.URL.Query() {
+		if len(v) > 0 {
+			request.Context[k] = v[0]
+		}
+	}
+
+	var proj, closeFiles, err = a.requestProject(r, name)
+	if err != nil {
+		writeAPIProjectError(w, name, err)
+		return
+	}
+	defer closeFiles()
+
+	file, ok := findAPIFile(w, proj, filePath)
+	if !ok {
+		return
+	}
+
+	if proj.Context == nil {
+		proj.Context = make(map[string]any)
+	}
+	maps.Copy(proj.Context, request.Context)
+
+	var content = new(bytes.Buffer)
+	if _, err = io.Copy(content, file); err != nil {
+		logger.Errorf("Failed to read file '%s' in project '%s': %v", file.GetPath(), name, err)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to read file")
+		return
+	}
+
+	if !quickfs.IsText(content.Bytes()) {
+		writeJSONError(w, http.StatusUnprocessableEntity, "Binary files cannot be rendered")
+		return
+	}
+
+	var renderedPath = new(bytes.Buffer)
+	if err = a.executeProjectTemplate(proj, renderedPath, filepath.ToSlash(file.GetPath())); err != nil {
+		writeJSONError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	var rendered = new(bytes.Buffer)
+	if err = a.executeProjectTemplate(proj, rendered, content.String()); err != nil {
+		writeJSONError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, &APIRenderResponse{
+		Path:         filepath.ToSlash(file.GetPath()),
+		RenderedPath: renderedPath.String(),
+		Content:      rendered.String(),
+	})
+}
+
+func (a *App) serveAPISearch(w http.ResponseWriter, r *http.Request) {
+	var query, results, err = a.searchRequest(r)
+	if err != nil {
+		logger.Errorf("Failed to search for '%s': %v", query, err)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to search")
+		return
+	}
+
+	if results == nil {
+		results = make([]*search.Result, 0)
+	}
+
+	writeJSON(w, http.StatusOK, &APISearchResponse{
+		Query:   query,
+		Results: results,
+	})
+}
+
+func (a *App) readAPIConfig(w http.ResponseWriter, st store.TemplateStore, name string) (*config.Project, bool) {
+	var tpl, err = st.Get(name)
+	if err != nil {
+		writeAPIProjectError(w, name, err)
+		return nil, false
+	}
+	defer tpl.Close()
+
+	proj, err := config.ReadYaml[config.Project](
+		bytes.NewReader(tpl.Config),
+	)
+	if err != nil {
+		logger.Errorf("Failed to read config for project '%s': %v", name, err)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to read project config")
+		return nil, false
+	}
+
+	return proj, true
+}
+
+func findAPIFile(w http.ResponseWriter, proj *config.Project, filePath []string) (*quickfs.FSFile, bool) {
+	var fileLike, err = proj.Root.Find(filePath)
+	if err != nil {
+		writeJSONError(w, http.StatusNotFound, "File not found")
+		return nil, false
+	}
+
+	var file, ok = fileLike.(*quickfs.FSFile)
+	if !ok {
+		writeJSONError(w, http.StatusBadRequest, "Path is a directory")
+		return nil, false
+	}
+
+	return file, true
+}
+
+func newAPIFile(fl quickfs.FileLike) *APIFile {
+	var file = &APIFile{
+		Name:  fl.GetName(),
+		Path:  filepath.ToSlash(fl.GetPath()),
+		IsDir: fl.IsDir(),
+	}
+
+	switch f := fl.(type) {
+	case *quickfs.FSFile:
+		file.Size = f.Size
+	case *quickfs.FSDirectory:
+		file.Size = f.Size()
+		file.Children = make([]*APIFile, 0)
+		f.ForEach(false, func(child quickfs.FileLike) (cancel bool, err error) {
+			file.Children = append(file.Children, newAPIFile(child))
+			return false, nil
+		})
+	}
+
+	return file
+}
+
+func writeAPIProjectError(w http.ResponseWriter, name string, err error) {
+	switch {
+	case errors.Is(err, store.ErrTemplateMissing):
+		writeJSONError(w, http.StatusNotFound, "Project not found")
+	case errors.Is(err, store.ErrTemplateName), errors.Is(err, config.ErrProjectInvalid):
+		writeJSONError(w, http.StatusBadRequest, "Invalid project name")
+	default:
+		logger.Errorf("Failed to read project '%s' for API: %v", name, err)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to read project")
+	}
+}
@@ -0,0 +1,113 @@
+package quickgo_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo"
+)
+
+func TestAPI(t *testing.T) {
+	var (
+		app  = newTestApp()
+		proj = newTestProject(t, map[string]string{
+			"README.md":             "{{ index .Context \"Greeting\" }} {{ .Name }}",
+			"{{ .Name }}/main.go":   "package main",
+			"src/{{ .Name }}.txt":   "{{ index .Context \"Extra\" }}",
+			"src/nested/empty.json": "{}",
+		})
+	)
+
+	if err := app.WriteProjectConfig(proj); err != nil {
+		t.Fatal(err)
+	}
+
+	var server = httptest.NewServer(app.HttpHandler())
+	defer server.Close()
+
+	var get = func(t *testing.T, method, path string, body string, status int, v any) []byte {
+		t.Helper()
+		var req, err = http.NewRequest(method, server.URL+quickgo.APIPathPrefix+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != status {
+			t.Fatalf("%s %s: expected status %d, got %d: %s", method, path, status, resp.StatusCode, data)
+		}
+
+		if v != nil {
+			if err = json.Unmarshal(data, v); err != nil {
+				t.Fatalf("%s %s: failed to decode response: %v", method, path, err)
+			}
+		}
+
+		return data
+	}
+
+	t.Run("list", func(t *testing.T) {
+		var list = new(quickgo.APIProjectList)
+		get(t, http.MethodGet, "projects", "", http.StatusOK, list)
+		if len(list.Projects) != 1 || list.Projects[0].Name != proj.Name {
+			t.Fatalf("expected 1 project named %q, got %v", proj.Name, list.Projects)
+		}
+	})
+
+	t.Run("project", func(t *testing.T) {
+		var project = new(quickgo.APIProject)
+		get(t, http.MethodGet, "projects/"+proj.Name, "", http.StatusOK, project)
+		if project.Config == nil || project.Config.Context["Greeting"] != "Hello" {
+			t.Fatalf("expected project config to be included, got %v", project.Config)
+		}
+	})
+
+	t.Run("tree", func(t *testing.T) {
+		var tree = new(quickgo.APIFile)
+		get(t, http.MethodGet, "projects/"+proj.Name+"/tree", "", http.StatusOK, tree)
+		if !tree.IsDir || len(tree.Children) != 3 {
+			t.Fatalf("expected root with 3 children, got %+v", tree)
+		}
+	})
+
+	t.Run("file", func(t *testing.T) {
+		var data = get(t, http.MethodGet, "projects/"+proj.Name+"/files/README.md", "", http.StatusOK, nil)
+		if string(data) != "{{ index .Context \"Greeting\" }} {{ .Name }}" {
+			t.Fatalf("expected raw file content, got %q", data)
+		}
+	})
+
+	t.Run("render", func(t *testing.T) {
+		var rendered = new(quickgo.APIRenderResponse)
+		get(t, http.MethodPost, "projects/"+proj.Name+"/render/src/{{ .Name }}.txt", `{"context": {"Extra": "World"}}`, http.StatusOK, rendered)
+		if rendered.Content != "World" {
+			t.Errorf("expected %q, got %q", "World", rendered.Content)
+		}
+		if rendered.RenderedPath != "src/test-project.txt" {
+			t.Errorf("expected %q, got %q", "src/test-project.txt", rendered.RenderedPath)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		get(t, http.MethodGet, "projects/missing", "", http.StatusNotFound, nil)
+		get(t, http.MethodGet, "projects/"+proj.Name+"/files/missing.txt", "", http.StatusNotFound, nil)
+		get(t, http.MethodGet, "projects/"+proj.Name+"/files/src", "", http.StatusBadRequest, nil)
+		get(t, http.MethodPost, "projects/"+proj.Name+"/render/README.md", "{", http.StatusBadRequest, nil)
+		get(t, http.MethodDelete, "projects/"+proj.Name, "", http.StatusMethodNotAllowed, nil)
+		get(t, http.MethodGet, "unknown", "", http.StatusNotFound, nil)
+	})
+}
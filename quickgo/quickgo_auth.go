@@ -0,0 +1,171 @@
+package quickgo
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/auth"
+	"github.com/Nigel2392/quickgo/v2/quickgo/config"
+	"github.com/Nigel2392/quickgo/v2/quickgo/logger"
+	"github.com/Nigel2392/quickgo/v2/quickgo/registry"
+	"github.com/Nigel2392/quickgo/v2/quickgo/store"
+	"github.com/pkg/errors"
+)
+
+// NewAuthenticator creates the authenticator for the server from the global configuration.
+// It returns nil if no authentication is configured.
+func NewAuthenticator(cfg *config.Auth) (auth.Authenticator, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	var chain = make(auth.Chain, 0, 2)
+	if len(cfg.Tokens) > 0 {
+		var tokens = make(auth.TokenAuthenticator, len(cfg.Tokens))
+		for _, t := range cfg.Tokens {
+			if t.Token == "" {
+				return nil, errors.Errorf("auth token '%s' cannot be empty", t.Name)
+			}
+
+			var perm, err = auth.ParsePermission(t.Permission)
+			if err != nil {
+				return nil, errors.Wrapf(err, "auth token '%s'", t.Name)
+			}
+
+			tokens[t.Token] = &auth.Principal{
+				Name:       t.Name,
+				Teams:      t.Teams,
+				Permission: perm,
+			}
+		}
+		chain = append(chain, tokens)
+	}
+
+	if cfg.Htpasswd != "" {
+		var hashes, err = auth.LoadHtpasswd(cfg.Htpasswd)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load htpasswd file %s", cfg.Htpasswd)
+		}
+
+		var users = make(map[string]*auth.Principal, len(cfg.Users))
+		for name, u := range cfg.Users {
+			var perm, err = auth.ParsePermission(u.Permission)
+			if err != nil {
+				return nil, errors.Wrapf(err, "auth user '%s'", name)
+			}
+
+			users[name] = &auth.Principal{
+				Name:       name,
+				Teams:      u.Teams,
+				Permission: perm,
+			}
+		}
+
+		chain = append(chain, &auth.HtpasswdAuthenticator{
+			Hashes: hashes,
+			Users:  users,
+		})
+	}
+
+	if len(chain) == 0 {
+		return nil, nil
+	}
+
+	return chain, nil
+}
+
+// authenticate authenticates the request with the app's authenticator.
+// A nil principal is returned for anonymous requests.
+func (a *App) authenticate(r *http.Request) (*auth.Principal, error) {
+	if a.Auth == nil {
+		return nil, nil
+	}
+	return a.Auth.Authenticate(r)
+}
+
+// authRequired reports whether the server rejects anonymous requests.
+func (a *App) authRequired() bool {
+	return a.Config != nil && a.Config.Auth != nil && a.Config.Auth.Required
+}
+
+// writeUnauthorized asks the client to authenticate.
+// Requests to the JSON endpoints receive a JSON error response.
+func (a *App) writeUnauthorized(w http.ResponseWriter, r *http.Request, message string) {
+	if a.Config != nil && a.Config.Auth != nil && a.Config.Auth.Htpasswd != "" {
+		w.Header().Add("WWW-Authenticate", `Basic realm="quickgo"`)
+	}
+	w.Header().Add("WWW-Authenticate", `Bearer realm="quickgo"`)
+
+	if strings.HasPrefix(r.URL.Path, APIPathPrefix) || strings.HasPrefix(r.URL.Path, registry.PathPrefix) {
+		writeJSONError(w, http.StatusUnauthorized, message)
+		return
+	}
+
+	http.Error(w, message, http.StatusUnauthorized)
+}
+
+// requestStore returns a view of the app's store which only contains
+// the templates the principal of the request is allowed to read.
+func (a *App) requestStore(r *http.Request) store.TemplateStore {
+	return &visibleStore{
+		TemplateStore: a.Store,
+		principal:     auth.FromContext(r.Context()),
+	}
+}
+
+// visibleStore hides the templates a principal is not allowed to read.
+// Hidden templates are reported as missing, to not leak that they exist.
+type visibleStore struct {
+	store.TemplateStore
+	principal *auth.Principal
+}
+
+func (s *visibleStore) canRead(tpl *store.Template) bool {
+	var proj, err = config.ReadYaml[config.Project](
+		bytes.NewReader(tpl.Config),
+	)
+	if err != nil {
+		logger.Warnf("Hiding template '%s' with invalid config: %v", tpl.Name, err)
+		return false
+	}
+	return s.principal.CanRead(proj.Visibility, proj.Teams)
+}
+
+func (s *visibleStore) List() ([]*store.Template, error) {
+	var templates, err = s.TemplateStore.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var visible = make([]*store.Template, 0, len(templates))
+	for _, tpl := range templates {
+		if s.canRead(tpl) {
+			visible = append(visible, tpl)
+		}
+	}
+	return visible, nil
+}
+
+func (s *visibleStore) Stat(name string) (*store.Metadata, error) {
+	var tpl, err = s.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	defer tpl.Close()
+	return &tpl.Metadata, nil
+}
+
+func (s *visibleStore) Get(name string) (*store.Template, error) {
+	var tpl, err = s.TemplateStore.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.canRead(tpl) {
+		tpl.Close()
+		return nil, store.ErrTemplateMissing
+	}
+
+	return tpl, nil
+}
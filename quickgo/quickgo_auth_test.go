@@ -0,0 +1,194 @@
+package quickgo_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo"
+	"github.com/Nigel2392/quickgo/v2/quickgo/auth"
+	"github.com/Nigel2392/quickgo/v2/quickgo/config"
+	"github.com/Nigel2392/quickgo/v2/quickgo/registry"
+)
+
+func TestAuth(t *testing.T) {
+	var app = newTestApp()
+	app.Auth = auth.TokenAuthenticator{
+		"backend":   {Name: "backend", Teams: []string{"backend"}},
+		"frontend":  {Name: "frontend", Teams: []string{"frontend"}},
+		"publisher": {Name: "publisher", Permission: auth.PermissionPublish},
+	}
+
+	var empty = new(bytes.Buffer)
+	if err := zip.NewWriter(empty).Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, cfg := range map[string]string{
+		"public":  "name: public\n",
+		"private": "name: private\nvisibility: private\n",
+		"team":    "name: team\nvisibility: team\nteams: [backend]\n",
+	} {
+		if err := app.Store.Put(name, []byte(cfg), bytes.NewReader(empty.Bytes())); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var server = httptest.NewServer(app.HttpHandler())
+	defer server.Close()
+
+	var tests = []struct {
+		token   string
+		visible []string
+	}{
+		{"", []string{"public"}},
+		{"frontend", []string{"private", "public"}},
+		{"backend", []string{"private", "public", "team"}},
+		{"publisher", []string{"private", "public", "team"}},
+	}
+
+	for _, test := range tests {
+		t.Run("visibility "+test.token, func(t *testing.T) {
+			var client = registry.NewClient(server.URL)
+			client.Token = test.token
+
+			var templates, err = client.List()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names = make([]string, 0, len(templates))
+			for _, tpl := range templates {
+				names = append(names, tpl.Name)
+			}
+
+			if !slices.Equal(names, test.visible) {
+				t.Fatalf("expected %v, got %v", test.visible, names)
+			}
+
+			for _, name := range []string{"public", "private", "team"} {
+				var resp, err = get(server.URL+quickgo.APIPathPrefix+"projects/"+name, test.token)
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+
+				var expected = http.StatusNotFound
+				if slices.Contains(test.visible, name) {
+					expected = http.StatusOK
+				}
+
+				if resp.StatusCode != expected {
+					t.Errorf("%s: expected status %d, got %d", name, expected, resp.StatusCode)
+				}
+			}
+		})
+	}
+
+	t.Run("invalid token", func(t *testing.T) {
+		var client = registry.NewClient(server.URL)
+		client.Token = "wrong"
+
+		var errResp *registry.ErrorResponse
+		if _, err := client.List(); !errors.As(err, &errResp) || errResp.Status != http.StatusUnauthorized {
+			t.Fatalf("expected 401 error response, got %v", err)
+		}
+	})
+
+	t.Run("required", func(t *testing.T) {
+		app.Config.Auth = &config.Auth{Required: true}
+		defer func() { app.Config.Auth = nil }()
+
+		var resp, err = get(server.URL+"/", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusUnauthorized || resp.Header.Get("WWW-Authenticate") == "" {
+			t.Fatalf("expected 401 with WWW-Authenticate header, got %d", resp.StatusCode)
+		}
+
+		if resp, err = get(server.URL+quickgo.APIPathPrefix+"projects", "backend"); err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d", resp.StatusCode)
+		}
+	})
+}
+
+func get(url, token string) (*http.Response, error) {
+	var req, err = http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return http.DefaultClient.Do(req)
+}
+
+func TestNewAuthenticator(t *testing.T) {
+	var a, err = quickgo.NewAuthenticator(&config.Auth{
+		Tokens: []*config.AuthToken{
+			{Name: "ci", Token: "secret", Permission: "publish"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r, _ = http.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+
+	p, err := a.Authenticate(r)
+	if err != nil || p == nil || !p.CanPublish() {
+		t.Fatalf("expected principal with publish permission, got %v (%v)", p, err)
+	}
+
+	if _, err = quickgo.NewAuthenticator(&config.Auth{
+		Tokens: []*config.AuthToken{{Name: "ci", Token: "secret", Permission: "admin"}},
+	}); !errors.Is(err, auth.ErrInvalidPermission) {
+		t.Errorf("expected ErrInvalidPermission, got %v", err)
+	}
+
+	if a, err = quickgo.NewAuthenticator(nil); a != nil || err != nil {
+		t.Errorf("expected no authenticator, got %v (%v)", a, err)
+	}
+}
+
+func TestLegacyPublishTokens(t *testing.T) {
+	var cfg, err = config.ReadYaml[config.QuickGo](strings.NewReader(
+		"publishTokens:\n  - legacy\nauth:\n  tokens:\n    - name: reader\n      token: reader\n",
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := quickgo.NewAuthenticator(cfg.AuthConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for token, publish := range map[string]bool{"legacy": true, "reader": false} {
+		var r, _ = http.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+
+		var p, err = a.Authenticate(r)
+		if err != nil || p == nil || p.CanPublish() != publish {
+			t.Errorf("expected token %s to authenticate with publish=%v, got %v (%v)", token, publish, p, err)
+		}
+	}
+
+	if len(cfg.Auth.Tokens) != 1 {
+		t.Errorf("expected the configured tokens not to change, got %d", len(cfg.Auth.Tokens))
+	}
+}
@@ -0,0 +1,323 @@
+package quickgo
+
+import (
+	"archive/zip"
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"fmt"
+	html_template "html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Nigel2392/goldcrest"
+	"github.com/Nigel2392/quickgo/v2/quickgo/auth"
+	"github.com/Nigel2392/quickgo/v2/quickgo/config"
+	"github.com/Nigel2392/quickgo/v2/quickgo/logger"
+	"github.com/Nigel2392/quickgo/v2/quickgo/store"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Archives larger than this are read from the store on every request.
+	maxCachedArchiveSize = 32 << 20
+
+	// The default maximum size of all projects in the cache together.
+	DefaultCacheSize = 256 << 20
+)
+
+type (
+	// projectCache keeps the configuration and files of stored projects in memory for the server.
+	// Entries are invalidated when the modification time or size of the template in the store changes.
+	//
+	// The least recently used projects are dropped when the cache grows larger than its maximum size.
+	projectCache struct {
+		mu      sync.Mutex
+		entries map[string]*list.Element // [name] => [*cachedProject]
+		recent  list.List                // Most recently used first.
+		size    int64
+	}
+
+	// cachedProject is the in-memory copy of a stored project.
+	cachedProject struct {
+		store.Metadata
+
+		// The raw (YAML) project configuration.
+		Config []byte
+
+		// The zipped project files, read from memory.
+		Archive *zip.Reader
+
+		// Who can read the project.
+		visibility string
+		teams      []string
+	}
+
+	// pageCache keeps the parsed page templates of the web UI.
+	pageCache struct {
+		mu    sync.Mutex
+		pages map[string]*html_template.Template
+		tag   string // Identifies the templates and static files, see templatesTag.
+	}
+)
+
+func init() {
+	// Drop the cached project as soon as it is saved, instead of waiting for the next stat.
+	goldcrest.Register(HookProjectAfterSave, 0, func(a *App, proj *config.Project) error {
+		a.InvalidateProject(proj.Name)
+		return nil
+	})
+}
+
+// ETag returns the entity tag of pages rendered from the project with the templates identified by templatesTag.
+// It changes when the project is saved again, when QuickGo is upgraded, or when the theme changes.
+func (c *cachedProject) ETag(templatesTag string) string {
+	return fmt.Sprintf(`"%x-%x%s-%s"`, c.ModTime.UnixNano(), c.Size, Version(), templatesTag)
+}
+
+// size returns the number of bytes the project takes up in memory.
+func (c *cachedProject) size() int64 {
+	return int64(len(c.Config)) + c.Size
+}
+
+// get returns the named project and marks it as recently used.
+func (c *projectCache) get(name string) (*cachedProject, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var elem, ok = c.entries[name]
+	if !ok {
+		return nil, false
+	}
+
+	c.recent.MoveToFront(elem)
+	return elem.Value.(*cachedProject), true
+}
+
+// put adds the project to the cache, and drops the least recently used projects
+// until the cache is no larger than maxSize.
+func (c *projectCache) put(entry *cachedProject, maxSize int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.remove(entry.Name)
+
+	if c.entries == nil {
+		c.entries = make(map[string]*list.Element)
+	}
+	c.entries[entry.Name] = c.recent.PushFront(entry)
+	c.size += entry.size()
+
+	for c.size > maxSize && c.recent.Len() > 0 {
+		c.remove(c.recent.Back().Value.(*cachedProject).Name)
+	}
+}
+
+// remove drops the named project, the lock must be held.
+func (c *projectCache) remove(name string) {
+	var elem, ok = c.entries[name]
+	if !ok {
+		return
+	}
+
+	c.recent.Remove(elem)
+	delete(c.entries, name)
+	c.size -= elem.Value.(*cachedProject).size()
+}
+
+// cacheSize returns the maximum size of the project cache.
+func (a *App) cacheSize() int64 {
+	if a.Config == nil || a.Config.CacheSize <= 0 {
+		return DefaultCacheSize
+	}
+	return a.Config.CacheSize
+}
+
+// InvalidateProject removes the named project from the server's cache.
+func (a *App) InvalidateProject(name string) {
+	a.projects.mu.Lock()
+	defer a.projects.mu.Unlock()
+	a.projects.remove(name)
+}
+
+// cachedProject returns the named project from the cache, (re)loading it if it changed in the store.
+// Projects which the principal of the request cannot read are reported as missing.
+//
+// Projects with large archives are not kept in the cache, their Archive is nil.
+func (a *App) cachedProject(r *http.Request, name string) (*cachedProject, error) {
+	if name == "" || name == "." || strings.ContainsAny(name, `/\`) {
+		return nil, config.ErrProjectName
+	}
+
+	var meta, err = a.Store.Stat(name)
+	if err != nil {
+		a.InvalidateProject(name)
+		return nil, errors.Wrapf(err, "failed to get project %s", name)
+	}
+
+	var entry, ok = a.projects.get(name)
+	if !ok || !entry.ModTime.Equal(meta.ModTime) || entry.Size != meta.Size {
+		if entry, err = a.loadCachedProject(name); err != nil {
+			return nil, err
+		}
+	}
+
+	if !auth.FromContext(r.Context()).CanRead(entry.visibility, entry.teams) {
+		return nil, errors.Wrapf(store.ErrTemplateMissing, "failed to get project %s", name)
+	}
+
+	return entry, nil
+}
+
+func (a *App) loadCachedProject(name string) (*cachedProject, error) {
+	var tpl, err = a.Store.Get(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get project %s", name)
+	}
+	defer tpl.Close()
+
+	proj, err := config.ReadYaml[config.Project](
+		bytes.NewReader(tpl.Config),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load YAML for project config %s", name)
+	}
+
+	var entry = &cachedProject{
+		Metadata:   tpl.Metadata,
+		Config:     tpl.Config,
+		visibility: proj.Visibility,
+		teams:      proj.Teams,
+	}
+
+	if tpl.Size > maxCachedArchiveSize {
+		return entry, nil
+	}
+
+	var archive = make([]byte, tpl.Size)
+	if _, err = io.ReadFull(io.NewSectionReader(tpl.Archive, 0, tpl.Size), archive); err != nil {
+		return nil, errors.Wrapf(err, "failed to read archive of project %s", name)
+	}
+
+	if entry.Archive, err = zip.NewReader(bytes.NewReader(archive), tpl.Size); err != nil {
+		return nil, errors.Wrapf(err, "failed to read zip file for project %s", name)
+	}
+
+	a.projects.put(entry, a.cacheSize())
+	return entry, nil
+}
+
+// requestProject reads the named project for the request, using the project cache.
+// The returned function must be called to close the project files.
+func (a *App) requestProject(r *http.Request, name string) (proj *config.Project, closeFiles func(), err error) {
+	var entry *cachedProject
+	if entry, err = a.cachedProject(r, name); err != nil {
+		return nil, nil, err
+	}
+	return a.openCachedProject(r, entry)
+}
+
+// openCachedProject builds a fresh project tree from the cached project.
+// The returned function must be called to close the project files.
+func (a *App) openCachedProject(r *http.Request, entry *cachedProject) (proj *config.Project, closeFiles func(), err error) {
+	if entry.Archive == nil {
+		return a.readProject(a.requestStore(r), entry.Name)
+	}
+	return a.loadProject(entry.Name, entry.Config, entry.Archive)
+}
+
+// notModified sets the caching headers for a page rendered from the project,
+// and reports whether the client's cached copy is still valid.
+// If so, a 304 response has been written.
+func (a *App) notModified(w http.ResponseWriter, r *http.Request, entry *cachedProject) bool {
+	var etag = entry.ETag(a.templatesTag())
+	var header = w.Header()
+	header.Set("ETag", etag)
+	header.Set("Last-Modified", entry.ModTime.UTC().Format(http.TimeFormat))
+	header.Set("Cache-Control", "no-cache")
+	header.Add("Vary", "Authorization")
+
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			if tag = strings.TrimSpace(tag); tag == etag || tag == "W/"+etag || tag == "*" {
+				w.WriteHeader(http.StatusNotModified)
+				return true
+			}
+		}
+		return false
+	}
+
+	if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil {
+		if !entry.ModTime.Truncate(time.Second).After(since) {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
+
+// pageTemplate returns the parsed page template with the given name.
+// Templates are parsed once, and must be cloned before they are executed.
+func (a *App) pageTemplate(name string) (*html_template.Template, error) {
+	a.pages.mu.Lock()
+	defer a.pages.mu.Unlock()
+
+	if tpl, ok := a.pages.pages[name]; ok {
+		return tpl, nil
+	}
+
+	var tpl, err = html_template.New("base").Funcs(a.templateFuncs(nil)).ParseFS(
+		a.templateFS(), append(a.Patterns, fmt.Sprintf("_templates/%s", name))...,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse template %s", name)
+	}
+
+	if a.pages.pages == nil {
+		a.pages.pages = make(map[string]*html_template.Template)
+	}
+	a.pages.pages[name] = tpl
+
+	return tpl, nil
+}
+
+// templatesTag returns a short hash of the page templates and static files of the web UI,
+// so that cached pages are not reused after the theme or its files changed.
+// Like the parsed templates, the hash is computed once.
+func (a *App) templatesTag() string {
+	a.pages.mu.Lock()
+	defer a.pages.mu.Unlock()
+
+	if a.pages.tag != "" {
+		return a.pages.tag
+	}
+
+	var h = sha256.New()
+	var err = fs.WalkDir(a.templateFS(), templatesDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		data, err := fs.ReadFile(a.templateFS(), path)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(h, "%s\x00%d\x00", path, len(data))
+		h.Write(data)
+		return nil
+	})
+	if err != nil {
+		// Without a hash the pages can still be served, they just cannot be revalidated after a theme change.
+		logger.Errorf("failed to hash the templates: %s", err)
+		return "templates"
+	}
+
+	a.pages.tag = fmt.Sprintf("%x", h.Sum(nil)[:8])
+	return a.pages.tag
+}
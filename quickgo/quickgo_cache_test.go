@@ -0,0 +1,167 @@
+package quickgo_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/store"
+)
+
+func TestProjectCache(t *testing.T) {
+	var (
+		app  = newTestApp()
+		proj = newTestProject(t, map[string]string{
+			"README.txt": "first version",
+		})
+	)
+
+	if err := app.WriteProjectConfig(proj); err != nil {
+		t.Fatal(err)
+	}
+
+	var server = httptest.NewServer(app.HttpHandler())
+	defer server.Close()
+
+	var get = func(t *testing.T, path string, header http.Header) (*http.Response, string) {
+		t.Helper()
+		var req, err = http.NewRequest(http.MethodGet, server.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var body, _ = io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	var resp, body = get(t, "/projects/test-project/README.txt", nil)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body, "first version") {
+		t.Fatalf("expected file page, got %d: %s", resp.StatusCode, body)
+	}
+
+	var etag = resp.Header.Get("ETag")
+	if etag == "" || resp.Header.Get("Last-Modified") == "" {
+		t.Fatalf("expected ETag and Last-Modified headers, got %v", resp.Header)
+	}
+
+	t.Run("if-none-match", func(t *testing.T) {
+		var resp, body = get(t, "/projects/test-project/README.txt", http.Header{"If-None-Match": {etag}})
+		if resp.StatusCode != http.StatusNotModified || body != "" {
+			t.Errorf("expected status 304 without body, got %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("if-modified-since", func(t *testing.T) {
+		var lastModified = resp.Header.Get("Last-Modified")
+		var resp, _ = get(t, "/config/test-project", http.Header{"If-Modified-Since": {lastModified}})
+		if resp.StatusCode != http.StatusNotModified {
+			t.Errorf("expected status 304, got %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("invalidate", func(t *testing.T) {
+		// Replace the template in the store directly, the cache must notice the change.
+		var archive = new(bytes.Buffer)
+		var zw = zip.NewWriter(archive)
+		var f, _ = zw.Create("README.txt")
+		io.WriteString(f, "second version")
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := app.Store.Put(proj.Name, []byte("name: test-project\n"), archive); err != nil {
+			t.Fatal(err)
+		}
+
+		var resp, body = get(t, "/projects/test-project/README.txt", http.Header{"If-None-Match": {etag}})
+		if resp.StatusCode != http.StatusOK || !strings.Contains(body, "second version") {
+			t.Fatalf("expected the new version of the file, got %d: %s", resp.StatusCode, body)
+		}
+
+		if resp.Header.Get("ETag") == etag {
+			t.Error("expected the ETag to change")
+		}
+	})
+}
+
+// countingStore counts the templates read from the store.
+type countingStore struct {
+	store.TemplateStore
+	gets map[string]int
+}
+
+func (s *countingStore) Get(name string) (*store.Template, error) {
+	s.gets[name]++
+	return s.TemplateStore.Get(name)
+}
+
+func TestProjectCacheSize(t *testing.T) {
+	var (
+		app      = newTestApp()
+		counting = &countingStore{TemplateStore: app.Store, gets: make(map[string]int)}
+		proj     = newTestProject(t, map[string]string{
+			"README.txt": "cached",
+		})
+		size int64
+	)
+
+	app.Store = counting
+
+	for _, name := range []string{"first", "second"} {
+		proj.Name = name
+		if err := app.WriteProjectConfig(proj); err != nil {
+			t.Fatal(err)
+		}
+
+		var tpl, err = app.Store.Get(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tpl.Close()
+		size += tpl.Size + int64(len(tpl.Config))
+	}
+
+	// Only one of the projects fits in the cache.
+	app.Config.CacheSize = size - 1
+
+	var server = httptest.NewServer(app.HttpHandler())
+	defer server.Close()
+
+	var get = func(name string) {
+		t.Helper()
+		var resp, err = http.Get(server.URL + "/projects/" + name + "/README.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status 200 for %s, got %d", name, resp.StatusCode)
+		}
+	}
+
+	clear(counting.gets)
+
+	get("first")
+	get("first")
+	if counting.gets["first"] != 1 {
+		t.Errorf("expected first to be read from the store once, got %d", counting.gets["first"])
+	}
+
+	get("second")
+	get("first")
+	if counting.gets["first"] != 2 {
+		t.Errorf("expected first to be dropped from the cache, got %d reads", counting.gets["first"])
+	}
+}
@@ -6,15 +6,15 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/Nigel2392/quickgo/v2/quickgo/command"
 	"github.com/Nigel2392/quickgo/v2/quickgo/config"
 	"github.com/Nigel2392/quickgo/v2/quickgo/js"
 	"github.com/Nigel2392/quickgo/v2/quickgo/logger"
 	"github.com/dop251/goja"
-	"github.com/kballard/go-shellquote"
 	"github.com/pkg/errors"
 )
 
@@ -42,6 +42,7 @@ func (a *App) ListJSFiles() ([]string, error) {
 	}
 
 	for _, d := range dir {
+		// Directories are not commands, this also hides the modules in the lib directory.
 		if d.IsDir() {
 			continue
 		}
@@ -99,209 +100,249 @@ func (a *App) SaveJS(path string) error {
 	return nil
 }
 
-func (a *App) ExecJS(targetDir string, scriptName string, rawArgs []string, args map[string]any) (err error) {
-	var (
-		scriptPath = GetQuickGoPath(
-			config.COMMANDS_DIR,
-			fmt.Sprintf("%s.js", scriptName),
-		)
+// ProjectScripts returns the JS commands declared in the scripts of the current project, sorted by name.
+// The project is read from the directory, which defaults to the working directory.
+//
+// If multiple scripts have the same name, the first one matched is used.
+func (a *App) ProjectScripts(directory string) ([]*CommandInfo, error) {
+	if a.ProjectConfig == nil {
+		return nil, config.ErrProjectMissing
+	}
 
-		script []byte
-		cmd    *js.Command
+	var (
+		scripts = make([]*CommandInfo, 0)
+		seen    = make(map[string]string)
 	)
 
-	logger.Debugf("Reading script '%s'", scriptPath)
+	directory = getTargetDirectory(directory)
+	for _, pattern := range a.ProjectConfig.Scripts {
+		var matches, err = filepath.Glob(filepath.Join(directory, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid script pattern %s", pattern)
+		}
+
+		for _, match := range matches {
+			if filepath.Ext(match) != ".js" {
+				continue
+			}
+
+			if s, err := os.Stat(match); err != nil || s.IsDir() {
+				continue
+			}
+
+			var (
+				name    = strings.TrimSuffix(filepath.Base(match), ".js")
+				rel, _  = filepath.Rel(directory, match)
+				relPath = filepath.ToSlash(rel)
+			)
+
+			if other, ok := seen[name]; ok {
+				if other != relPath {
+					logger.Warnf("Script '%s' is declared twice, using %s instead of %s", name, other, relPath)
+				}
+				continue
+			}
 
-	script, err = os.ReadFile(scriptPath)
+			seen[name] = relPath
+			scripts = append(scripts, &CommandInfo{
+				Name:   name,
+				Script: relPath,
+			})
+		}
+	}
+
+	slices.SortFunc(scripts, func(a, b *CommandInfo) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return scripts, nil
+}
+
+// ExecJS executes the global JS command with the name from the commands directory.
+func (a *App) ExecJS(targetDir string, scriptName string, rawArgs []string, args map[string]any) (err error) {
+	scriptPath, err := globalScriptPath(scriptName)
 	if err != nil {
-		return errors.Wrapf(err, "failed to read script %s", script)
+		return err
 	}
+	return a.execJSFile(targetDir, scriptName, scriptPath, rawArgs, args)
+}
 
-	logger.Debugf("'%s' has access to project config: %v", scriptName, a.ProjectConfig != nil)
+// ExecProjectScript executes the JS command with the name from the scripts of the current project.
+func (a *App) ExecProjectScript(targetDir string, scriptName string, rawArgs []string, args map[string]any) error {
+	var scriptPath, err = a.projectScriptPath(targetDir, scriptName)
+	if err != nil {
+		return err
+	}
+	return a.execJSFile(targetDir, scriptName, scriptPath, rawArgs, args)
+}
+
+// ExecJSStep runs the inline script or the named JS command of a step, it is used for command.RunJS.
+//
+// Named commands are looked up in the scripts of the current project first, then in the global commands.
+// The arguments of the step are available as os.args, the environment of the step as quickgo.environ.
+// The values returned by the script are returned, to be added to the environment of the next steps.
+func (a *App) ExecJSStep(step command.Step, env map[string]any) (map[string]any, error) {
 	var (
-		projectName string
-		projectPath string
+		targetDir, _ = env["projectPath"].(string)
+		args         = make([]string, len(step.Args))
+		name         string
+		scriptPath   string
+		source       string
 	)
-	if a.ProjectConfig != nil {
-		projectName = a.ProjectConfig.Name
-		projectPath = getTargetDirectory(
-			targetDir,
-		)
+
+	for i, arg := range step.Args {
+		args[i] = command.ExpandArg(arg, env)
 	}
 
-	var (
-		vm            = goja.New()
-		quickgoModule = map[string]any{
-			"app":         a,
-			"project":     a.ProjectConfig,
-			"config":      a.Config,
-			"environ":     args,
-			"projectName": projectName,
-			"projectPath": projectPath,
-			"projectLocked": func() bool {
-				return config.IsLocked(targetDir) == nil
-			},
+	if step.Script != "" {
+		// Modules required by inline scripts are resolved against the project directory.
+		name = step.Name
+		scriptPath = filepath.Join(getTargetDirectory(targetDir), config.PROJECT_CONFIG_NAME)
+		source = "function main() {" + step.Script + "\n}"
+	} else {
+		var err error
+		name = step.JSCommand
+		scriptPath, err = a.projectScriptPath(targetDir, name)
+		if errors.Is(err, config.ErrCommandMissing) || errors.Is(err, config.ErrProjectMissing) {
+			scriptPath, err = globalScriptPath(name)
+		}
+		if err != nil {
+			return nil, err
 		}
-		fsModule = map[string]any{
-			"cleanPath": filepath.Clean,
-			"joinPath":  filepath.Join,
-			"absPath": func(path string) string {
-				path, err = filepath.Abs(path)
-				if err != nil {
-					logger.Error(err)
-					return ""
-				}
-				return path
-			},
-			"getWd": func() string {
-				return getTargetDirectory(targetDir)
-			},
-			"writeFile": func(path string, data goja.Value) error {
-				var b []byte
-				if vm.InstanceOf(data, vm.Get("Uint8Array").ToObject(vm)) {
-					b = data.Export().([]byte)
-				} else if d, ok := data.Export().(goja.ArrayBuffer); ok {
-					b = d.Bytes()
-				} else {
-					b = []byte(data.String())
-				}
 
-				err = os.WriteFile(path, b, os.ModePerm)
-				if err != nil {
-					logger.Error(err)
-					return err
-				}
-				logger.Debugf("File '%s' written by '%s'", path, scriptPath)
-				return nil
-			},
-			"readFile": func(path string) goja.Value {
-				var data []byte
-				var err error
-				if data, err = os.ReadFile(path); err != nil {
-					logger.Error(err)
-					return goja.Undefined()
-				}
+		data, err := os.ReadFile(scriptPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read script %s", scriptPath)
+		}
+		source = string(data)
+	}
 
-				logger.Debugf("File '%s' read by '%s'", path, scriptPath)
+	var result, err = a.runJS(targetDir, name, scriptPath, source, args, env)
+	if err != nil {
+		return nil, errors.Wrapf(err, "script '%s' failed", name)
+	}
 
-				var arr = vm.NewArrayBuffer(data)
-				return vm.ToValue(arr)
-			},
-			"readTextFile": func(path string) string {
-				var data, err = os.ReadFile(path)
-				if err != nil {
-					logger.Error(err)
-					return ""
-				}
+	return result.Values, nil
+}
 
-				logger.Debugf("File '%s' read by '%s'", path, scriptPath)
+// globalScriptPath returns the path of the global JS command with the name.
+func globalScriptPath(scriptName string) (string, error) {
+	// Modules in the lib directory (or anywhere else) are not commands.
+	if scriptName == "" || strings.ContainsAny(scriptName, `/\`) || strings.HasPrefix(scriptName, ".") {
+		return "", errors.Wrapf(config.ErrCommandMissing, "invalid command name '%s'", scriptName)
+	}
 
-				return string(data)
-			},
-			"readDir": func(path string) goja.Value {
+	var scriptPath = GetQuickGoPath(
+		config.COMMANDS_DIR,
+		fmt.Sprintf("%s.js", scriptName),
+	)
 
-				path, err = filepath.Abs(path)
-				if err != nil {
-					logger.Error(err)
-					return goja.Undefined()
-				}
+	if _, err := os.Stat(scriptPath); err != nil && os.IsNotExist(err) {
+		return "", errors.Wrapf(config.ErrCommandMissing, "command '%s' not found", scriptName)
+	}
 
-				var dir, err = os.ReadDir(path)
-				if err != nil {
-					logger.Error(err)
-					return goja.Undefined()
-				}
+	return scriptPath, nil
+}
 
-				var d = make([]any, len(dir))
-				for i, f := range dir {
-					d[i] = map[string]any{
-						"name":        f.Name(),
-						"isDirectory": f.IsDir(),
-						"isFile":      f.Type().IsRegular(),
-						"path": filepath.Join(
-							path, f.Name(),
-						),
-					}
-				}
+// projectScriptPath returns the path of the JS command with the name from the scripts of the current project.
+func (a *App) projectScriptPath(targetDir string, scriptName string) (string, error) {
+	var scripts, err = a.ProjectScripts(targetDir)
+	if err != nil {
+		return "", err
+	}
 
-				return vm.ToValue(
-					vm.NewArray(d...),
-				)
-			},
+	for _, script := range scripts {
+		if script.Name == scriptName {
+			return filepath.Join(
+				getTargetDirectory(targetDir),
+				filepath.FromSlash(script.Script),
+			), nil
+		}
+	}
+
+	return "", errors.Wrapf(config.ErrCommandMissing, "script '%s' not found", scriptName)
+}
+
+// execJSFile executes the JS command in the file at scriptPath.
+func (a *App) execJSFile(targetDir string, scriptName string, scriptPath string, rawArgs []string, args map[string]any) (err error) {
+	logger.Debugf("Reading script '%s'", scriptPath)
+
+	script, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read script %s", scriptPath)
+	}
+
+	result, err := a.runJS(targetDir, scriptName, scriptPath, string(script), rawArgs, args)
+	if err != nil {
+		if _, ok := err.(*js.CommandError); !ok {
+			return errors.Wrapf(err, "failed to execute script '%s'", scriptName)
 		}
-		osModule = map[string]any{
+	}
+
+	var s = fmt.Sprintf("Script '%s' executed with exit code %d", scriptName, result.Importance)
+	if result.Message != "" {
+		s = fmt.Sprintf("%s: %s", s, result.Message)
+	}
+
+	if result.Importance == 0 {
+		logger.Info(s)
+	} else {
+		logger.Error(s)
+	}
+
+	return js.ErrExitCode
+}
+
+// runJS runs the JS source with the quickgo, os, fs, prompt and http modules, and returns the result of its main function.
+// The scriptPath is used to resolve modules and in log messages, it does not have to exist.
+func (a *App) runJS(targetDir string, scriptName string, scriptPath string, source string, rawArgs []string, args map[string]any) (result *js.CommandResult, err error) {
+	var cmd *js.Command
+
+	logger.Debugf("'%s' has access to project config: %v", scriptName, a.ProjectConfig != nil)
+
+	perms, err := a.scriptPermissions(targetDir, source)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid permissions in script %s", scriptPath)
+	}
+
+	var (
+		vm            = goja.New()
+		loop          = js.NewEventLoop(vm)
+		fsModule      = newJSFS(vm, loop, perms, targetDir, scriptPath)
+		quickgoModule = newJSQuickGo(a, perms, fsModule, targetDir)
+		osModule      = map[string]any{
 			"args": rawArgs,
-			"getEnv": func(key string) string {
-				return os.Getenv(key)
+			"getEnv": func(key string) (string, error) {
+				if err := perms.CheckEnv(key); err != nil {
+					return "", err
+				}
+				return os.Getenv(key), nil
 			},
 			"setEnv": func(key, value string) error {
+				if err := perms.CheckEnv(key); err != nil {
+					return err
+				}
 				logger.Debugf("Setting environment variable '%s'='%s'", key, value)
 				return os.Setenv(key, value)
 			},
-			"exec": func(cmd string, commandArgs ...string) goja.Value {
-
-				logger.Debugf(
-					"Executing command from '%s': %s %s",
-					scriptPath, cmd, strings.Join(commandArgs, " "),
-				)
-
-				// Parse the command arguments if the command is provided as a single string.
-				// Example: echo "Hello, World!" -> echo, ["Hello, World!"]
-				if strings.Index(cmd, " ") > 0 {
-					var s = strings.SplitN(cmd, " ", 2)
-					cmd = s[0]
-					if len(commandArgs) == 0 {
-						commandArgs, err = shellquote.Split(
-							s[1],
-						)
-						if err != nil {
-							logger.Errorf(
-								"failed to split command arguments for: %s: %s",
-								scriptPath, err,
-							)
-							return vm.ToValue(map[string]any{
-								"stdout":   "",
-								"exitCode": 1,
-								"error": fmt.Sprintf(
-									"failed to split command arguments: %s", err,
-								),
-							})
-						}
-					} else {
-						logger.Warnf(
-							"command arguments are provided, but the command is already split for: %s",
-							scriptPath,
-						)
-					}
+			"exec": func(call goja.FunctionCall) goja.Value {
+				var opts, err = parseExecCall(vm, call)
+				if err != nil {
+					panic(vm.NewGoError(err))
 				}
-
-				var command = exec.Command(cmd, commandArgs...)
-				var s = new(strings.Builder)
-
-				command.Stdout = s
-				command.Stderr = s
-
-				if err = command.Run(); err != nil {
-					logger.Errorf(
-						"failed to execute command: %s: %s",
-						scriptPath, err,
-					)
-					return vm.ToValue(map[string]any{
-						"stdout":   s.String(),
-						"exitCode": 1,
-						"error":    err,
-					})
+				result, err := execCommand(perms, scriptPath, targetDir, opts)
+				if err != nil {
+					panic(vm.NewGoError(err))
 				}
-
-				logger.Debugf(
-					"Command executed from '%s': %s %s",
-					scriptPath, cmd, strings.Join(commandArgs, " "),
-				)
-
-				return vm.ToValue(map[string]any{
-					"stdout":   s.String(),
-					"exitCode": command.ProcessState.ExitCode(),
-					"error":    err,
+				return vm.ToValue(result)
+			},
+			"execAsync": func(call goja.FunctionCall) goja.Value {
+				var opts, err = parseExecCall(vm, call)
+				return loop.Async(func() (any, error) {
+					if err != nil {
+						return nil, err
+					}
+					return execCommand(perms, scriptPath, targetDir, opts)
 				})
 			},
 		}
@@ -318,10 +359,19 @@ func (a *App) ExecJS(targetDir string, scriptName string, rawArgs []string, args
 	cmd = js.NewScript(
 		"main",
 		js.WithVM(vm),
+		js.WithEventLoop(loop),
+		js.WithModules(
+			scriptPath,
+			GetQuickGoPath(config.COMMANDS_DIR, config.COMMANDS_LIB_DIR),
+			GetQuickGoPath(config.COMMANDS_DIR),
+			getTargetDirectory(targetDir),
+		),
 		js.WithGlobals(map[string]any{
-			"quickgo": quickgoModule,
+			"quickgo": quickgoModule.module(args),
 			"os":      osModule,
-			"fs":      fsModule,
+			"fs":      fsModule.module(),
+			"prompt":  promptModule(newPrompter(args)),
+			"http":    newJSHTTP(vm, loop, perms, scriptPath).module(),
 		}),
 	)
 
@@ -337,17 +387,7 @@ func (a *App) ExecJS(targetDir string, scriptName string, rawArgs []string, args
 			},
 		})
 		vm.Set("base64Encode", func(data goja.Value) string {
-			if vm.InstanceOf(data, vm.Get("Uint8Array").ToObject(vm)) {
-				var arr = data.Export().([]byte)
-				return base64.StdEncoding.EncodeToString(arr)
-			}
-
-			if data, ok := data.Export().(goja.ArrayBuffer); ok {
-				return base64.StdEncoding.EncodeToString(data.Bytes())
-			}
-
-			var s = data.String()
-			return base64.StdEncoding.EncodeToString([]byte(s))
+			return base64.StdEncoding.EncodeToString(jsBytes(vm, data))
 		})
 		vm.Set("base64Decode", func(data string) goja.Value {
 			var arr, err = base64.StdEncoding.DecodeString(data)
@@ -365,23 +405,38 @@ func (a *App) ExecJS(targetDir string, scriptName string, rawArgs []string, args
 
 	logger.Debugf("Executing script '%s'", scriptPath)
 
-	result, err := cmd.Run(string(script))
+	return cmd.Run(source)
+}
+
+// scriptPermissions returns the permissions of a script.
+// These are the permissions in the header of the script and the permissions of the current project,
+// relative paths are resolved against the target directory.
+func (a *App) scriptPermissions(targetDir string, source string) (*js.Permissions, error) {
+	var perms, err = js.ParsePermissions(source)
 	if err != nil {
-		if _, ok := err.(*js.CommandError); !ok {
-			return errors.Wrapf(err, "failed to execute script '%s'", scriptName)
-		}
+		return nil, err
 	}
 
-	var s = fmt.Sprintf("Script '%s' executed with exit code %d", scriptName, result.Importance)
-	if result.Message != "" {
-		s = fmt.Sprintf("%s: %s", s, result.Message)
+	if a.ProjectConfig != nil {
+		perms = perms.Merge(a.ProjectConfig.Permissions)
 	}
 
-	if result.Importance == 0 {
-		logger.Info(s)
-	} else {
-		logger.Error(s)
+	if a.AllowAll {
+		perms.All = true
 	}
 
-	return js.ErrExitCode
+	return perms.Resolve(getTargetDirectory(targetDir)), nil
+}
+
+// jsBytes returns the bytes of a Uint8Array or ArrayBuffer, other values are converted to a string.
+func jsBytes(vm *goja.Runtime, data goja.Value) []byte {
+	if vm.InstanceOf(data, vm.Get("Uint8Array").ToObject(vm)) {
+		return data.Export().([]byte)
+	}
+
+	if d, ok := data.Export().(goja.ArrayBuffer); ok {
+		return d.Bytes()
+	}
+
+	return []byte(data.String())
 }
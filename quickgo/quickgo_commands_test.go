@@ -0,0 +1,289 @@
+package quickgo_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/command"
+	"github.com/Nigel2392/quickgo/v2/quickgo/config"
+	"github.com/Nigel2392/quickgo/v2/quickgo/js"
+)
+
+func TestProjectScripts(t *testing.T) {
+	var (
+		app  = newTestApp()
+		proj = newTestProject(t, map[string]string{
+			".quickgo/commands/greet.js": `
+				// @permissions allow-write=greeting.txt
+				const { suffix } = require("./lib/suffix");
+				function main() {
+					fs.writeFile("greeting.txt", quickgo.projectName + ": " + quickgo.environ.greeting + suffix);
+					return Success("done");
+				}
+			`,
+			".quickgo/commands/lib/suffix.js": `exports.suffix = "!";`,
+			".quickgo/commands/notes.txt":     "not a script",
+			"scripts/greet.js":                `function main() { return Fail("shadowed"); }`,
+			"scripts/fail.js":                 `function main() { return Fail("failed"); }`,
+		})
+	)
+
+	proj.Scripts = []string{".quickgo/commands/*", "scripts/*.js"}
+	app.ProjectConfig = proj
+
+	var scripts, err = app.ProjectScripts("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names = make(map[string]string)
+	for _, script := range scripts {
+		names[script.Name] = script.Script
+	}
+
+	if len(names) != 2 || names["greet"] != ".quickgo/commands/greet.js" || names["fail"] != "scripts/fail.js" {
+		t.Fatalf("unexpected scripts: %v", names)
+	}
+
+	err = app.ExecProjectScript("", "greet", nil, map[string]any{"greeting": "Hello"})
+	if err != nil && !errors.Is(err, js.ErrExitCode) {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile("greeting.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "test-project: Hello!" {
+		t.Errorf("expected the script to write the greeting, got %q", data)
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		var err = app.ExecProjectScript("", "does-not-exist", nil, nil)
+		if !errors.Is(err, config.ErrCommandMissing) {
+			t.Errorf("expected %v, got %v", config.ErrCommandMissing, err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var proj = &config.Project{Name: "invalid", Scripts: []string{"../outside/*.js"}}
+		if err := proj.Validate(); !errors.Is(err, config.ErrProjectInvalid) {
+			t.Errorf("expected %v, got %v", config.ErrProjectInvalid, err)
+		}
+	})
+}
+
+func TestJSSteps(t *testing.T) {
+	var (
+		app  = newTestApp()
+		proj = newTestProject(t, map[string]string{
+			"scripts/write.js": `
+				function main() {
+					fs.writeFile(os.args[0], quickgo.environ.projectName + " " + quickgo.environ.version);
+				}
+			`,
+		})
+	)
+
+	proj.Scripts = []string{"scripts/*.js"}
+	proj.Permissions = &js.Permissions{Write: []string{"."}}
+	app.ProjectConfig = proj
+
+	command.RunJS = app.ExecJSStep
+	defer func() { command.RunJS = nil }()
+
+	var steps = &command.StepList{
+		Steps: []command.Step{
+			{Name: "version", Script: `return { version: "v" + quickgo.environ.major + ".2.3" };`},
+			{Name: "write", JSCommand: "write", Args: []string{"$projectName.txt"}},
+		},
+	}
+
+	var env = map[string]any{"projectName": "test-project", "major": "1"}
+	if err := steps.Execute(env); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := env["version"]; ok {
+		t.Error("expected the environment passed to Execute to be unchanged")
+	}
+
+	var data, err = os.ReadFile("test-project.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "test-project v1.2.3" {
+		t.Errorf("expected the value of the first step in the second step, got %q", data)
+	}
+
+	t.Run("fail", func(t *testing.T) {
+		var steps = &command.StepList{
+			Steps: []command.Step{
+				{Name: "fail", Script: `return Fail("something went wrong");`},
+			},
+		}
+
+		var err = steps.Execute(nil)
+		if err == nil || !strings.Contains(err.Error(), "something went wrong") {
+			t.Errorf("expected the message of the script in the error, got %v", err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var steps = &command.StepList{
+			Steps: []command.Step{
+				{Name: "both", Command: "echo", Script: `return;`},
+			},
+		}
+
+		if err := steps.Execute(nil); !errors.Is(err, command.ErrStepInvalid) {
+			t.Errorf("expected %v, got %v", command.ErrStepInvalid, err)
+		}
+	})
+}
+
+func TestAsyncScripts(t *testing.T) {
+	var (
+		app  = newTestApp()
+		proj = newTestProject(t, map[string]string{
+			"a.txt": "a",
+			"b.txt": "b",
+			"scripts/async.js": `
+				// @permissions allow-read=. allow-write=.
+				const delay = (ms) => new Promise((resolve) => setTimeout(resolve, ms));
+
+				async function main() {
+					const [a, b] = await Promise.all([
+						fs.readTextFileAsync("a.txt"),
+						fs.readTextFileAsync("b.txt"),
+						delay(5),
+					]);
+					await fs.writeFileAsync("ab.txt", a + b);
+
+					let rejected = false;
+					try {
+						await fs.readTextFileAsync("missing.txt");
+					} catch (e) {
+						rejected = true;
+					}
+					return { ab: a + b, rejected: rejected };
+				}
+			`,
+		})
+	)
+
+	proj.Scripts = []string{"scripts/*.js"}
+	app.ProjectConfig = proj
+
+	var values, err = app.ExecJSStep(command.Step{Name: "async", JSCommand: "async"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if values["ab"] != "ab" || values["rejected"] != true {
+		t.Errorf("unexpected values: %v", values)
+	}
+
+	data, err := os.ReadFile("ab.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "ab" {
+		t.Errorf("expected the file to be written, got %q", data)
+	}
+}
+
+func TestScriptPermissions(t *testing.T) {
+	var (
+		app     = newTestApp()
+		outside = t.TempDir()
+		proj    = newTestProject(t, map[string]string{
+			"data/in.txt": "inside",
+			"scripts/copy.js": `
+				// @permissions allow-read=data allow-write=out.txt
+				function main() {
+					fs.writeFile(os.args[0], fs.readTextFile(os.args[1]));
+				}
+			`,
+			"scripts/env.js": `
+				// @permissions allow-env=QUICKGO_TEST_ALLOWED
+				function main() {
+					return { allowed: os.getEnv("QUICKGO_TEST_ALLOWED"), denied: os.getEnv("QUICKGO_TEST_DENIED") };
+				}
+			`,
+			"scripts/run.js": `
+				function main() {
+					return { stdout: os.exec("echo hello").stdout };
+				}
+			`,
+		})
+	)
+
+	proj.Scripts = []string{"scripts/*.js"}
+	app.ProjectConfig = proj
+
+	if err := os.Symlink(outside, "data/link"); err != nil {
+		t.Fatal(err)
+	}
+
+	var run = func(name string, args ...string) (map[string]any, error) {
+		return app.ExecJSStep(command.Step{Name: name, JSCommand: name, Args: args}, nil)
+	}
+
+	var tests = []struct {
+		name   string
+		script string
+		args   []string
+		denied string
+	}{
+		{name: "allowed", script: "copy", args: []string{"out.txt", "data/in.txt"}},
+		{name: "write outside", script: "copy", args: []string{outside + "/out.txt", "data/in.txt"}, denied: "write access"},
+		{name: "write other file", script: "copy", args: []string{"other.txt", "data/in.txt"}, denied: "write access"},
+		{name: "read outside", script: "copy", args: []string{"out.txt", "quickgo.yaml"}, denied: "read access"},
+		{name: "read through symlink", script: "copy", args: []string{"out.txt", "data/link/secret.txt"}, denied: "read access"},
+		{name: "env", script: "env", denied: `env access to "QUICKGO_TEST_DENIED"`},
+		{name: "run", script: "run", denied: `run access to "echo"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var _, err = run(test.script, test.args...)
+			if test.denied == "" && err != nil {
+				t.Fatal(err)
+			}
+
+			if test.denied != "" && (err == nil || !strings.Contains(err.Error(), test.denied)) {
+				t.Fatalf("expected the script to be denied %s, got %v", test.denied, err)
+			}
+		})
+	}
+
+	t.Run("project", func(t *testing.T) {
+		proj.Permissions = &js.Permissions{Env: []string{"QUICKGO_TEST_DENIED"}, Run: []string{"echo"}}
+		defer func() { proj.Permissions = nil }()
+
+		t.Setenv("QUICKGO_TEST_DENIED", "granted")
+		var values, err = run("env")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if values["denied"] != "granted" {
+			t.Errorf("expected the project permissions to be added, got %v", values)
+		}
+	})
+
+	t.Run("allow all", func(t *testing.T) {
+		app.AllowAll = true
+		defer func() { app.AllowAll = false }()
+
+		var values, err = run("run")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.TrimSpace(values["stdout"].(string)) != "hello" {
+			t.Errorf("expected the command to run, got %v", values)
+		}
+	})
+}
@@ -0,0 +1,250 @@
+package quickgo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/js"
+	"github.com/Nigel2392/quickgo/v2/quickgo/logger"
+	"github.com/dop251/goja"
+	"github.com/kballard/go-shellquote"
+	"github.com/pkg/errors"
+)
+
+// The time to wait for the output of a command after it was killed because of its timeout.
+const execWaitDelay = time.Second
+
+// execOptions are the options of a command run with os.exec.
+type execOptions struct {
+	Command string            // The executable to run.
+	Args    []string          // The arguments of the command.
+	Cwd     string            // The working directory, relative paths are relative to the target directory.
+	Env     map[string]string // Extra environment variables, added to the environment of the process.
+	Stdin   []byte            // The input of the command.
+	Timeout time.Duration     // Kill the command after the timeout, no timeout if zero.
+	Stream  bool              // Write the output of the command to the console while it is running.
+}
+
+// parseExecCall returns the options of a call to os.exec or os.execAsync.
+//
+// The command can be called as:
+//
+//	os.exec("go test ./...")
+//	os.exec("go", "test", "./...")
+//	os.exec("go", ["test", "./..."], { cwd: "app", env: { CGO_ENABLED: "0" }, stdin: "", timeout: 60000, stream: true })
+//	os.exec({ command: "go", args: ["test", "./..."], timeout: 60000 })
+//
+// The options object is always the last argument, the timeout is in milliseconds.
+func parseExecCall(vm *goja.Runtime, call goja.FunctionCall) (*execOptions, error) {
+	var (
+		opts = new(execOptions)
+		args = call.Arguments
+	)
+
+	if len(args) > 0 {
+		if obj, ok := args[len(args)-1].(*goja.Object); ok && obj.ClassName() == "Object" {
+			if err := opts.parseObject(vm, obj); err != nil {
+				return nil, err
+			}
+			args = args[:len(args)-1]
+		}
+	}
+
+	for i, arg := range args {
+		var values []string
+		switch {
+		case goja.IsUndefined(arg) || goja.IsNull(arg):
+			return nil, errors.Errorf("invalid argument %d for os.exec: %s", i, arg)
+		case isArray(arg):
+			if err := vm.ExportTo(arg, &values); err != nil {
+				return nil, errors.Wrapf(err, "invalid argument %d for os.exec", i)
+			}
+		default:
+			values = []string{arg.String()}
+		}
+
+		if opts.Command == "" && len(values) > 0 {
+			opts.Command, values = values[0], values[1:]
+		}
+		opts.Args = append(opts.Args, values...)
+	}
+
+	if opts.Command == "" {
+		return nil, errors.New("no command provided to os.exec")
+	}
+
+	// Parse the command arguments if the command is provided as a single string.
+	// Example: echo "Hello, World!" -> echo, ["Hello, World!"]
+	// If arguments are provided as well, those are used instead of the arguments in the string.
+	if strings.Index(opts.Command, " ") > 0 {
+		var s = strings.SplitN(opts.Command, " ", 2)
+		if len(opts.Args) > 0 {
+			logger.Warnf(
+				"command arguments are provided, but the command is already split: %s",
+				opts.Command,
+			)
+			opts.Command = s[0]
+			return opts, nil
+		}
+
+		var split, err = shellquote.Split(s[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to split command arguments of %s", opts.Command)
+		}
+		opts.Command, opts.Args = s[0], split
+	}
+
+	return opts, nil
+}
+
+func isArray(v goja.Value) bool {
+	var obj, ok = v.(*goja.Object)
+	return ok && obj.ClassName() == "Array"
+}
+
+func (o *execOptions) parseObject(vm *goja.Runtime, obj *goja.Object) error {
+	var set = func(name string) goja.Value {
+		var v = obj.Get(name)
+		if v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+			return nil
+		}
+		return v
+	}
+
+	if v := set("command"); v != nil {
+		o.Command = v.String()
+	}
+
+	if v := set("args"); v != nil {
+		if err := vm.ExportTo(v, &o.Args); err != nil {
+			return errors.Wrap(err, "invalid args option for os.exec")
+		}
+	}
+
+	if v := set("cwd"); v != nil {
+		o.Cwd = v.String()
+	}
+
+	if v := set("env"); v != nil {
+		var env map[string]any
+		if err := vm.ExportTo(v, &env); err != nil {
+			return errors.Wrap(err, "invalid env option for os.exec")
+		}
+		o.Env = make(map[string]string, len(env))
+		for key, value := range env {
+			o.Env[key] = fmt.Sprint(value)
+		}
+	}
+
+	if v := set("stdin"); v != nil {
+		o.Stdin = jsBytes(vm, v)
+	}
+
+	if v := set("timeout"); v != nil {
+		o.Timeout = time.Duration(v.ToInteger()) * time.Millisecond
+	}
+
+	if v := set("stream"); v != nil {
+		o.Stream = v.ToBoolean()
+	}
+
+	return nil
+}
+
+// execCommand runs a command for os.exec.
+// Relative working directories are resolved against the target directory, which is also the default.
+// An error is only returned if the script is not allowed to run the command,
+// other errors are returned in the result, together with the output and the exit code.
+func execCommand(perms *js.Permissions, scriptPath string, targetDir string, opts *execOptions) (map[string]any, error) {
+	if err := perms.CheckRun(opts.Command); err != nil {
+		return nil, err
+	}
+
+	var cwd = getTargetDirectory(targetDir)
+	if opts.Cwd != "" && filepath.IsAbs(opts.Cwd) {
+		cwd = opts.Cwd
+	} else if opts.Cwd != "" {
+		cwd = filepath.Join(cwd, opts.Cwd)
+	}
+
+	var ctx, cancel = context.Background(), context.CancelFunc(func() {})
+	if opts.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+	}
+	defer cancel()
+
+	logger.Debugf(
+		"Executing command from '%s' in '%s': %s %s",
+		scriptPath, cwd, opts.Command, strings.Join(opts.Args, " "),
+	)
+
+	var (
+		command        = exec.CommandContext(ctx, opts.Command, opts.Args...)
+		stdout, stderr = new(bytes.Buffer), new(bytes.Buffer)
+	)
+
+	command.Dir = cwd
+	command.WaitDelay = execWaitDelay
+	command.Stdout = stdout
+	command.Stderr = stderr
+
+	if opts.Stream {
+		command.Stdout = io.MultiWriter(stdout, os.Stdout)
+		command.Stderr = io.MultiWriter(stderr, os.Stderr)
+	}
+
+	if opts.Stdin != nil {
+		command.Stdin = bytes.NewReader(opts.Stdin)
+	}
+
+	if len(opts.Env) > 0 {
+		command.Env = os.Environ()
+		for key, value := range opts.Env {
+			command.Env = append(command.Env, key+"="+value)
+		}
+	}
+
+	var (
+		err      = command.Run()
+		timedOut = ctx.Err() == context.DeadlineExceeded
+		exitCode = -1
+		errMsg   any
+	)
+
+	// The exit code is -1 if the command could not be started or was killed.
+	if command.ProcessState != nil {
+		exitCode = command.ProcessState.ExitCode()
+	}
+
+	if timedOut {
+		err = errors.Errorf("command timed out after %s", opts.Timeout)
+	}
+
+	if err != nil {
+		logger.Errorf(
+			"failed to execute command: %s: %s",
+			scriptPath, err,
+		)
+		errMsg = err.Error()
+	} else {
+		logger.Debugf(
+			"Command executed from '%s': %s %s",
+			scriptPath, opts.Command, strings.Join(opts.Args, " "),
+		)
+	}
+
+	return map[string]any{
+		"stdout":   stdout.String(),
+		"stderr":   stderr.String(),
+		"exitCode": exitCode,
+		"timedOut": timedOut,
+		"error":    errMsg,
+	}, nil
+}
@@ -0,0 +1,90 @@
+package quickgo_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/command"
+	"github.com/Nigel2392/quickgo/v2/quickgo/js"
+)
+
+func TestExecScripts(t *testing.T) {
+	var (
+		app  = newTestApp()
+		proj = newTestProject(t, map[string]string{
+			"sub/file.txt": "",
+			"scripts/exec.js": `
+				// @permissions allow-run=sh,cat,pwd,sleep
+				async function main() {
+					const split = os.exec("sh", "-c", "echo out; echo err >&2; exit 3");
+					const stdin = os.exec("cat", { stdin: "from stdin" });
+					const cwd = os.exec("pwd", { cwd: "sub" });
+					const env = os.exec("sh", ["-c", "echo $QUICKGO_EXEC_TEST"], { env: { QUICKGO_EXEC_TEST: 42 } });
+					const timeout = os.exec({ command: "sleep", args: ["5"], timeout: 50 });
+					const str = os.exec("sh -c 'echo \"quoted string\"'");
+					const async = await os.execAsync("cat", { stdin: "async" });
+					const missing = os.exec("sh", "-c", "exit 0", { cwd: "does-not-exist" });
+					return {
+						stdout: split.stdout, stderr: split.stderr, exitCode: split.exitCode, error: split.error,
+						stdin: stdin.stdout, cwd: cwd.stdout.trim(), env: env.stdout.trim(),
+						timedOut: timeout.timedOut, timeoutCode: timeout.exitCode,
+						str: str.stdout.trim(), async: async.stdout, missing: missing.exitCode,
+					};
+				}
+			`,
+		})
+	)
+
+	proj.Scripts = []string{"scripts/*.js"}
+	app.ProjectConfig = proj
+
+	var values, err = app.ExecJSStep(command.Step{Name: "exec", JSCommand: "exec"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expected = map[string]any{
+		"stdout":      "out\n",
+		"stderr":      "err\n",
+		"exitCode":    int64(3),
+		"error":       "exit status 3",
+		"stdin":       "from stdin",
+		"cwd":         filepath.Join(wd, "sub"),
+		"env":         "42",
+		"timedOut":    true,
+		"timeoutCode": int64(-1),
+		"str":         "quoted string",
+		"async":       "async",
+		"missing":     int64(-1),
+	}
+
+	for key, value := range expected {
+		if values[key] != value {
+			t.Errorf("expected %s to be %v (%T), got %v (%T)", key, value, value, values[key], values[key])
+		}
+	}
+
+	t.Run("split command with arguments", func(t *testing.T) {
+		proj.Permissions = &js.Permissions{Run: []string{"sh"}}
+		defer func() { proj.Permissions = nil }()
+
+		// The arguments in the command string are ignored, like before options were supported.
+		var values, err = app.ExecJSStep(command.Step{
+			Name:   "split",
+			Script: `return { stdout: os.exec("sh -c 'exit 1'", "-c", "echo kept").stdout };`,
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if values["stdout"] != "kept\n" {
+			t.Errorf("expected the provided arguments to be used, got %q", values["stdout"])
+		}
+	})
+}
@@ -0,0 +1,223 @@
+package quickgo
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/js"
+	"github.com/Nigel2392/quickgo/v2/quickgo/logger"
+	"github.com/dop251/goja"
+	"github.com/pkg/errors"
+)
+
+// The timeout of HTTP requests made by JS commands, unless the request has its own timeout.
+const fetchTimeout = 30 * time.Second
+
+// The maximum number of redirects followed by HTTP requests made by JS commands.
+const fetchMaxRedirects = 10
+
+type (
+	// jsHTTP implements the http module of JS commands.
+	// The hosts of requests and redirects must be allowed by the net permissions of the script.
+	jsHTTP struct {
+		vm         *goja.Runtime
+		loop       *js.EventLoop
+		perms      *js.Permissions
+		client     *http.Client
+		scriptPath string
+	}
+
+	// fetchOptions are the options of a request made with http.fetch or http.request.
+	fetchOptions struct {
+		Method  string
+		URL     *url.URL
+		Headers http.Header
+		Body    []byte
+		Timeout time.Duration // No timeout if zero.
+	}
+)
+
+func newJSHTTP(vm *goja.Runtime, loop *js.EventLoop, perms *js.Permissions, scriptPath string) *jsHTTP {
+	var h = &jsHTTP{
+		vm:         vm,
+		loop:       loop,
+		perms:      perms,
+		scriptPath: scriptPath,
+	}
+
+	h.client = &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= fetchMaxRedirects {
+				return errors.Errorf("stopped after %d redirects", fetchMaxRedirects)
+			}
+			return perms.CheckNet(req.URL.Host)
+		},
+	}
+
+	return h
+}
+
+// module returns the functions of the http module.
+//
+// fetch returns a promise which resolves with the response, request returns the response itself.
+// Responses with an error status are returned as well, only failed requests throw an error.
+func (h *jsHTTP) module() map[string]any {
+	return map[string]any{
+		"fetch": func(rawURL string, options goja.Value) goja.Value {
+			var opts, err = h.parseOptions(rawURL, options)
+			return h.loop.Async(func() (any, error) {
+				if err != nil {
+					return nil, err
+				}
+				return h.do(opts)
+			})
+		},
+		"request": func(rawURL string, options goja.Value) (map[string]any, error) {
+			var opts, err = h.parseOptions(rawURL, options)
+			if err != nil {
+				return nil, err
+			}
+			return h.do(opts)
+		},
+	}
+}
+
+// parseOptions returns the options of a request, it must be called on the loop.
+//
+// The options are the method, headers, body, json and timeout (in milliseconds).
+// The json option is sent as the body with JSON.stringify, with application/json as the content type.
+func (h *jsHTTP) parseOptions(rawURL string, options goja.Value) (*fetchOptions, error) {
+	var u, err = url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid URL %s", rawURL)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.Errorf("invalid URL %s, only http and https are supported", rawURL)
+	}
+
+	var opts = &fetchOptions{
+		Method:  http.MethodGet,
+		URL:     u,
+		Headers: make(http.Header),
+		Timeout: fetchTimeout,
+	}
+
+	if options == nil || goja.IsUndefined(options) || goja.IsNull(options) {
+		return opts, nil
+	}
+
+	var (
+		obj = options.ToObject(h.vm)
+		set = func(name string) goja.Value {
+			var v = obj.Get(name)
+			if v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+				return nil
+			}
+			return v
+		}
+	)
+
+	if v := set("method"); v != nil {
+		opts.Method = strings.ToUpper(v.String())
+	}
+
+	if v := set("headers"); v != nil {
+		var headers = v.ToObject(h.vm)
+		for _, key := range headers.Keys() {
+			opts.Headers.Set(key, headers.Get(key).String())
+		}
+	}
+
+	if v := set("body"); v != nil {
+		opts.Body = jsBytes(h.vm, v)
+	}
+
+	if v := set("json"); v != nil {
+		var stringify, _ = goja.AssertFunction(h.vm.Get("JSON").ToObject(h.vm).Get("stringify"))
+		var data, err = stringify(goja.Undefined(), v)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encode the json option")
+		}
+
+		opts.Body = []byte(data.String())
+		if opts.Headers.Get("Content-Type") == "" {
+			opts.Headers.Set("Content-Type", "application/json")
+		}
+	}
+
+	if v := set("timeout"); v != nil {
+		opts.Timeout = time.Duration(v.ToInteger()) * time.Millisecond
+	}
+
+	return opts, nil
+}
+
+// do makes the request and reads the response, it can be called from any goroutine.
+func (h *jsHTTP) do(opts *fetchOptions) (map[string]any, error) {
+	if err := h.perms.CheckNet(opts.URL.Host); err != nil {
+		return nil, err
+	}
+
+	var ctx, cancel = context.Background(), context.CancelFunc(func() {})
+	if opts.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+	}
+	defer cancel()
+
+	var body io.Reader
+	if opts.Body != nil {
+		body = bytes.NewReader(opts.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, opts.Method, opts.URL.String(), body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid request to %s", opts.URL)
+	}
+	req.Header = opts.Headers
+
+	logger.Debugf("Request from '%s': %s %s", h.scriptPath, opts.Method, opts.URL)
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the response of %s", opts.URL)
+	}
+
+	var headers = make(map[string]any, len(resp.Header))
+	for key, values := range resp.Header {
+		headers[strings.ToLower(key)] = strings.Join(values, ", ")
+	}
+
+	return map[string]any{
+		"url":        resp.Request.URL.String(),
+		"status":     resp.StatusCode,
+		"statusText": http.StatusText(resp.StatusCode),
+		"ok":         resp.StatusCode >= 200 && resp.StatusCode < 300,
+		"headers":    headers,
+		"text": func() string {
+			return string(data)
+		},
+		"json": func() (goja.Value, error) {
+			var parse, _ = goja.AssertFunction(h.vm.Get("JSON").ToObject(h.vm).Get("parse"))
+			var v, err = parse(goja.Undefined(), h.vm.ToValue(string(data)))
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid JSON in the response of %s", opts.URL)
+			}
+			return v, nil
+		},
+		"arrayBuffer": func() goja.Value {
+			return h.vm.ToValue(h.vm.NewArrayBuffer(data))
+		},
+	}, nil
+}
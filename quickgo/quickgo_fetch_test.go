@@ -0,0 +1,146 @@
+package quickgo_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/command"
+	"github.com/Nigel2392/quickgo/v2/quickgo/js"
+)
+
+func TestFetchScripts(t *testing.T) {
+	var mux = http.NewServeMux()
+	mux.HandleFunc("/repos", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]any{
+			"method":      r.Method,
+			"name":        body["name"],
+			"contentType": r.Header.Get("Content-Type"),
+			"token":       r.Header.Get("Authorization"),
+		})
+	})
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, r.Body)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(5 * time.Second):
+		case <-r.Context().Done():
+		}
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Query().Get("to"), http.StatusFound)
+	})
+
+	var server = httptest.NewServer(mux)
+	defer server.Close()
+
+	var (
+		app      = newTestApp()
+		proj     = newTestProject(t, nil)
+		u, _     = url.Parse(server.URL)
+		localURL = "http://localhost:" + u.Port()
+	)
+
+	proj.Permissions = &js.Permissions{Net: []string{u.Host}}
+	app.ProjectConfig = proj
+
+	var run = func(script string) (map[string]any, error) {
+		return app.ExecJSStep(
+			command.Step{Name: "fetch", Script: script},
+			map[string]any{"url": server.URL, "localURL": localURL},
+		)
+	}
+
+	var values, err = run(`
+		const url = quickgo.environ.url;
+		return (async () => {
+			const created = await http.fetch(url + "/repos", {
+				method: "post",
+				headers: { Authorization: "Bearer token" },
+				json: { name: "my-repo" },
+			});
+			const repo = created.json();
+
+			const echo = http.request(url + "/echo", { method: "PUT", body: "hello" });
+			const missing = http.request(url + "/missing");
+
+			let timedOut = "";
+			try {
+				await http.fetch(url + "/slow", { timeout: 50 });
+			} catch (e) {
+				timedOut = e.message;
+			}
+
+			return {
+				status: created.status,
+				ok: created.ok,
+				contentType: created.headers["content-type"],
+				method: repo.method,
+				name: repo.name,
+				sentType: repo.contentType,
+				token: repo.token,
+				echo: echo.text(),
+				missing: missing.status,
+				missingOk: missing.ok,
+				timedOut: timedOut,
+			};
+		})();
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expected = map[string]any{
+		"status":      int64(201),
+		"ok":          true,
+		"contentType": "application/json",
+		"method":      "POST",
+		"name":        "my-repo",
+		"sentType":    "application/json",
+		"token":       "Bearer token",
+		"echo":        "hello",
+		"missing":     int64(404),
+		"missingOk":   false,
+	}
+
+	for key, value := range expected {
+		if values[key] != value {
+			t.Errorf("expected %s to be %v (%T), got %v (%T)", key, value, value, values[key], values[key])
+		}
+	}
+
+	if timedOut, _ := values["timedOut"].(string); !strings.Contains(timedOut, "deadline exceeded") {
+		t.Errorf("expected the request to time out, got %q", timedOut)
+	}
+
+	t.Run("denied", func(t *testing.T) {
+		for _, script := range []string{
+			`http.request(quickgo.environ.localURL + "/echo")`,
+			`http.request(quickgo.environ.url + "/redirect?to=" + encodeURIComponent(quickgo.environ.localURL + "/echo"))`,
+		} {
+			if _, err := run(script); err == nil || !strings.Contains(err.Error(), "permission denied: net access") {
+				t.Errorf("expected %s to be denied, got %v", script, err)
+			}
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := run(`http.request("file:///etc/passwd")`); err == nil || !strings.Contains(err.Error(), "only http and https") {
+			t.Errorf("expected an error for an invalid scheme, got %v", err)
+		}
+	})
+}
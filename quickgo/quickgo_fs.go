@@ -0,0 +1,416 @@
+package quickgo
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/js"
+	"github.com/Nigel2392/quickgo/v2/quickgo/logger"
+	"github.com/dop251/goja"
+	"github.com/pkg/errors"
+)
+
+// jsFS implements the fs module of JS commands.
+//
+// Relative paths are resolved against the target directory.
+// Every function checks the permissions of the script and throws an error if it fails.
+type jsFS struct {
+	vm         *goja.Runtime
+	loop       *js.EventLoop
+	perms      *js.Permissions
+	dir        string // The target directory.
+	scriptPath string
+
+	// Temporary directories created by the script, these can always be read and written.
+	tempMu sync.Mutex
+	temp   []string
+}
+
+func newJSFS(vm *goja.Runtime, loop *js.EventLoop, perms *js.Permissions, targetDir string, scriptPath string) *jsFS {
+	return &jsFS{
+		vm:         vm,
+		loop:       loop,
+		perms:      perms,
+		dir:        getTargetDirectory(targetDir),
+		scriptPath: scriptPath,
+	}
+}
+
+// module returns the functions of the fs module.
+func (f *jsFS) module() map[string]any {
+	return map[string]any{
+		"cleanPath": filepath.Clean,
+		"joinPath":  filepath.Join,
+		"absPath": func(path string) string {
+			return f.path(path)
+		},
+		"getWd": func() string {
+			return f.dir
+		},
+		"writeFile":    f.writeFile,
+		"readFile":     f.readFile,
+		"readTextFile": f.readTextFile,
+		"readDir":      f.readDir,
+		"mkdir":        f.mkdir,
+		"remove":       f.remove,
+		"rename":       f.rename,
+		"copy":         f.copy,
+		"stat":         f.stat,
+		"exists":       f.exists,
+		"glob":         f.glob,
+		"chmod":        f.chmod,
+		"makeTempDir":  f.makeTempDir,
+		"readFileAsync": func(path string) goja.Value {
+			return f.loop.Async(func() (any, error) {
+				if err := f.checkRead(path); err != nil {
+					return nil, err
+				}
+				return os.ReadFile(f.path(path))
+			})
+		},
+		"readTextFileAsync": func(path string) goja.Value {
+			return f.loop.Async(func() (any, error) {
+				if err := f.checkRead(path); err != nil {
+					return nil, err
+				}
+				var data, err = os.ReadFile(f.path(path))
+				return string(data), err
+			})
+		},
+		"writeFileAsync": func(path string, data goja.Value) goja.Value {
+			var b = jsBytes(f.vm, data)
+			return f.loop.Async(func() (any, error) {
+				if err := f.checkWrite(path); err != nil {
+					return nil, err
+				}
+				return nil, os.WriteFile(f.path(path), b, os.ModePerm)
+			})
+		},
+		"readDirAsync": func(path string) goja.Value {
+			return f.loop.Async(func() (any, error) {
+				if err := f.checkRead(path); err != nil {
+					return nil, err
+				}
+				return readDirEntries(f.path(path))
+			})
+		},
+	}
+}
+
+// path returns the absolute path, relative paths are relative to the target directory.
+func (f *jsFS) path(path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(f.dir, path)
+}
+
+func (f *jsFS) checkRead(path string) error {
+	var err = f.perms.CheckRead(f.path(path))
+	if err != nil && f.inTempDir(path) {
+		return nil
+	}
+	return err
+}
+
+func (f *jsFS) checkWrite(path string) error {
+	var err = f.perms.CheckWrite(f.path(path))
+	if err != nil && f.inTempDir(path) {
+		return nil
+	}
+	return err
+}
+
+func (f *jsFS) inTempDir(path string) bool {
+	f.tempMu.Lock()
+	defer f.tempMu.Unlock()
+
+	var perms = &js.Permissions{Read: f.temp}
+	return perms.CheckRead(f.path(path)) == nil
+}
+
+func (f *jsFS) writeFile(path string, data goja.Value) error {
+	if err := f.checkWrite(path); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(f.path(path), jsBytes(f.vm, data), os.ModePerm); err != nil {
+		return err
+	}
+
+	logger.Debugf("File '%s' written by '%s'", path, f.scriptPath)
+	return nil
+}
+
+func (f *jsFS) readFile(path string) (goja.Value, error) {
+	if err := f.checkRead(path); err != nil {
+		return nil, err
+	}
+
+	var data, err = os.ReadFile(f.path(path))
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debugf("File '%s' read by '%s'", path, f.scriptPath)
+	return f.vm.ToValue(f.vm.NewArrayBuffer(data)), nil
+}
+
+func (f *jsFS) readTextFile(path string) (string, error) {
+	if err := f.checkRead(path); err != nil {
+		return "", err
+	}
+
+	var data, err = os.ReadFile(f.path(path))
+	if err != nil {
+		return "", err
+	}
+
+	logger.Debugf("File '%s' read by '%s'", path, f.scriptPath)
+	return string(data), nil
+}
+
+func (f *jsFS) readDir(path string) (goja.Value, error) {
+	if err := f.checkRead(path); err != nil {
+		return nil, err
+	}
+
+	var entries, err = readDirEntries(f.path(path))
+	if err != nil {
+		return nil, err
+	}
+
+	return f.vm.ToValue(f.vm.NewArray(entries...)), nil
+}
+
+// mkdir creates the directory and its parents, like mkdir -p.
+func (f *jsFS) mkdir(path string) error {
+	if err := f.checkWrite(path); err != nil {
+		return err
+	}
+	return os.MkdirAll(f.path(path), os.ModePerm)
+}
+
+// remove removes the file or empty directory.
+// Directories with files are only removed with { recursive: true }.
+func (f *jsFS) remove(path string, options map[string]any) error {
+	if err := f.checkWrite(path); err != nil {
+		return err
+	}
+
+	if recursive, _ := options["recursive"].(bool); recursive {
+		return os.RemoveAll(f.path(path))
+	}
+	return os.Remove(f.path(path))
+}
+
+func (f *jsFS) rename(from, to string) error {
+	if err := f.checkWrite(from); err != nil {
+		return err
+	}
+	if err := f.checkWrite(to); err != nil {
+		return err
+	}
+	return os.Rename(f.path(from), f.path(to))
+}
+
+// copy copies the file or directory, directories are copied recursively.
+// Symbolic links are copied as links, the files they point to are not copied.
+func (f *jsFS) copy(from, to string) error {
+	if err := f.checkRead(from); err != nil {
+		return err
+	}
+	if err := f.checkWrite(to); err != nil {
+		return err
+	}
+
+	var src, dst = f.path(from), f.path(to)
+	if rel, err := filepath.Rel(src, dst); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return errors.Errorf("cannot copy %s into itself", src)
+	}
+
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		var target = filepath.Join(dst, rel)
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		default:
+			return copyFile(path, target, info.Mode().Perm())
+		}
+	})
+}
+
+func (f *jsFS) stat(path string) (map[string]any, error) {
+	if err := f.checkRead(path); err != nil {
+		return nil, err
+	}
+
+	var p = f.path(path)
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := os.Lstat(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		"name":        info.Name(),
+		"path":        p,
+		"size":        info.Size(),
+		"mode":        int64(info.Mode().Perm()),
+		"modTime":     info.ModTime().UnixMilli(),
+		"isDirectory": info.IsDir(),
+		"isFile":      info.Mode().IsRegular(),
+		"isSymlink":   link.Mode()&fs.ModeSymlink != 0,
+	}, nil
+}
+
+func (f *jsFS) exists(path string) (bool, error) {
+	if err := f.checkRead(path); err != nil {
+		return false, err
+	}
+
+	var _, err = os.Stat(f.path(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// glob returns the paths matching the pattern, see filepath.Match for the syntax.
+// Relative patterns return paths relative to the target directory.
+func (f *jsFS) glob(pattern string) ([]string, error) {
+	// The directory before the first meta character must be readable.
+	var base = pattern
+	if i := strings.IndexAny(pattern, `*?[\`); i >= 0 {
+		base = filepath.Dir(pattern[:i+1])
+	}
+	if err := f.checkRead(base); err != nil {
+		return nil, err
+	}
+
+	var matches, err = filepath.Glob(f.path(pattern))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid pattern %s", pattern)
+	}
+
+	// Patterns like */../../secret/* can still match files outside of the directory,
+	// matches which the script cannot read are left out.
+	// Always return a list, also when nothing matched.
+	var allowed = make([]string, 0, len(matches))
+	for _, match := range matches {
+		if f.checkRead(match) != nil {
+			continue
+		}
+
+		if !filepath.IsAbs(pattern) {
+			match, _ = filepath.Rel(f.dir, match)
+		}
+		allowed = append(allowed, match)
+	}
+
+	return allowed, nil
+}
+
+// chmod changes the permission bits of the file, for example fs.chmod("run.sh", 0o755).
+func (f *jsFS) chmod(path string, mode int64) error {
+	if err := f.checkWrite(path); err != nil {
+		return err
+	}
+	return os.Chmod(f.path(path), fs.FileMode(mode).Perm())
+}
+
+// makeTempDir creates a new temporary directory, which the script can always read and write.
+// The directory is not removed automatically.
+func (f *jsFS) makeTempDir(prefix string) (string, error) {
+	var dir, err = os.MkdirTemp("", prefix)
+	if err != nil {
+		return "", err
+	}
+
+	dir, err = filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", err
+	}
+
+	f.tempMu.Lock()
+	f.temp = append(f.temp, dir)
+	f.tempMu.Unlock()
+
+	logger.Debugf("Temporary directory '%s' created by '%s'", dir, f.scriptPath)
+	return dir, nil
+}
+
+// copyFile copies the contents of the file at src to dst.
+func copyFile(src, dst string, mode fs.FileMode) error {
+	var in, err = os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// readDirEntries returns the entries of the directory for fs.readDir.
+func readDirEntries(path string) ([]any, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var d = make([]any, len(dir))
+	for i, f := range dir {
+		d[i] = map[string]any{
+			"name":        f.Name(),
+			"isDirectory": f.IsDir(),
+			"isFile":      f.Type().IsRegular(),
+			"path": filepath.Join(
+				path, f.Name(),
+			),
+		}
+	}
+
+	return d, nil
+}
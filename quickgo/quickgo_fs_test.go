@@ -0,0 +1,147 @@
+package quickgo_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/command"
+	"github.com/Nigel2392/quickgo/v2/quickgo/js"
+)
+
+func TestFSScripts(t *testing.T) {
+	var (
+		app  = newTestApp()
+		proj = newTestProject(t, map[string]string{
+			"target/src/a.txt":     "a",
+			"target/src/sub/b.txt": "b",
+			"target/run.sh":        "#!/bin/sh",
+			"secret/key.txt":       "secret",
+		})
+	)
+
+	proj.Permissions = &js.Permissions{Read: []string{"."}, Write: []string{"."}}
+	app.ProjectConfig = proj
+
+	var target, err = filepath.Abs("target")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var run = func(script string) (map[string]any, error) {
+		return app.ExecJSStep(
+			command.Step{Name: "fs", Script: script},
+			map[string]any{"projectPath": target},
+		)
+	}
+
+	values, err := run(`
+		fs.mkdir("out/nested/deep");
+		fs.copy("src", "out/copy");
+		fs.rename("out/copy/a.txt", "out/copy/renamed.txt");
+		fs.chmod("run.sh", 0o700);
+
+		const tmp = fs.makeTempDir("quickgo-test-");
+		fs.writeFile(fs.joinPath(tmp, "tmp.txt"), "tmp");
+		const tmpText = fs.readTextFile(fs.joinPath(tmp, "tmp.txt"));
+		fs.remove(tmp, { recursive: true });
+
+		let missing = "";
+		try {
+			fs.readTextFile("missing.txt");
+		} catch (e) {
+			missing = e.message;
+		}
+
+		let notEmpty = false;
+		try {
+			fs.remove("out");
+		} catch (e) {
+			notEmpty = true;
+		}
+
+		const stat = fs.stat("out/copy/sub/b.txt");
+		return {
+			deep: fs.exists("out/nested/deep"),
+			renamed: fs.readTextFile("out/copy/renamed.txt"),
+			copied: fs.readTextFile("out/copy/sub/b.txt"),
+			oldName: fs.exists("out/copy/a.txt"),
+			glob: fs.glob("src/*/*.txt").join(","),
+			mode: fs.stat("run.sh").mode,
+			size: stat.size,
+			isFile: stat.isFile,
+			tmp: tmp,
+			tmpText: tmpText,
+			tmpExists: fs.exists(tmp),
+			missing: missing,
+			notEmpty: notEmpty,
+			wd: fs.absPath("x"),
+		};
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expected = map[string]any{
+		"deep":      true,
+		"renamed":   "a",
+		"copied":    "b",
+		"oldName":   false,
+		"glob":      filepath.Join("src", "sub", "b.txt"),
+		"mode":      int64(0o700),
+		"size":      int64(1),
+		"isFile":    true,
+		"tmpText":   "tmp",
+		"tmpExists": false,
+		"notEmpty":  true,
+		"wd":        filepath.Join(target, "x"),
+	}
+
+	for key, value := range expected {
+		if values[key] != value {
+			t.Errorf("expected %s to be %v (%T), got %v (%T)", key, value, value, values[key], values[key])
+		}
+	}
+
+	if missing, _ := values["missing"].(string); !strings.Contains(missing, "no such file") {
+		t.Errorf("expected an error for a missing file, got %q", missing)
+	}
+
+	if _, err := os.Stat(filepath.Join(target, "out", "copy", "renamed.txt")); err != nil {
+		t.Errorf("expected paths to be relative to the target directory: %v", err)
+	}
+
+	t.Run("denied", func(t *testing.T) {
+		for _, script := range []string{
+			`fs.copy("src", "../outside")`,
+			`fs.remove("..", { recursive: true })`,
+			`fs.rename("src", "../src")`,
+			`fs.exists("../quickgo.yaml")`,
+			`fs.glob("../*")`,
+			`fs.chmod("/", 0o777)`,
+		} {
+			if _, err := run(script); err == nil || !strings.Contains(err.Error(), "permission denied") {
+				t.Errorf("expected %s to be denied, got %v", script, err)
+			}
+		}
+	})
+
+	t.Run("glob outside", func(t *testing.T) {
+		var values, err = run(`return { matches: fs.glob("*/../../secret/*").join(",") };`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if values["matches"] != "" {
+			t.Errorf("expected no matches outside of the allowed directory, got %q", values["matches"])
+		}
+	})
+
+	t.Run("copy into itself", func(t *testing.T) {
+		for _, dst := range []string{"src/sub/copy", "src/..cache"} {
+			if _, err := run(`fs.copy("src", "` + dst + `")`); err == nil || !strings.Contains(err.Error(), "into itself") {
+				t.Errorf("expected an error for %s, got %v", dst, err)
+			}
+		}
+	})
+}
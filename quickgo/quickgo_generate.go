@@ -0,0 +1,295 @@
+package quickgo
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/config"
+	"github.com/Nigel2392/quickgo/v2/quickgo/logger"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ArchiveZip   = "zip"    // A zip archive, this is the default.
+	ArchiveTarGz = "tar.gz" // A gzip compressed tar archive.
+)
+
+var ErrArchiveFormat = errors.New("unknown archive format, expected 'zip' or 'tar.gz'")
+
+type (
+	// GenerateRequest is the JSON request body for generating a project on the server.
+	GenerateRequest struct {
+		// Extra context for the project templates.
+		// This is merged into the project's own context.
+		Context map[string]any `json:"context"`
+
+		// The format of the generated archive, "zip" (default) or "tar.gz".
+		Format string `json:"format"`
+	}
+
+	// archiveWriter writes files to a zip or tar.gz archive.
+	archiveWriter interface {
+		WriteDir(name string) error
+		WriteFile(name string, content []byte) error
+		Close() error
+	}
+
+	zipArchiveWriter struct {
+		zf *zip.Writer
+	}
+
+	tarArchiveWriter struct {
+		gz  *gzip.Writer
+		tw  *tar.Writer
+		now time.Time
+	}
+)
+
+func newArchiveWriter(w io.Writer, format string) (archiveWriter, error) {
+	switch format {
+	case "", ArchiveZip:
+		return &zipArchiveWriter{zf: zip.NewWriter(w)}, nil
+	case ArchiveTarGz:
+		var gz = gzip.NewWriter(w)
+		return &tarArchiveWriter{gz: gz, tw: tar.NewWriter(gz), now: time.Now()}, nil
+	}
+	return nil, errors.Wrapf(ErrArchiveFormat, "format %q", format)
+}
+
+// ArchiveContentType returns the content type and file extension of the archive format.
+func ArchiveContentType(format string) (contentType, ext string) {
+	if format == ArchiveTarGz {
+		return "application/gzip", ".tar.gz"
+	}
+	return "application/zip", ".zip"
+}
+
+func (z *zipArchiveWriter) WriteDir(name string) error {
+	var _, err = z.zf.Create(name + "/")
+	return err
+}
+
+func (z *zipArchiveWriter) WriteFile(name string, content []byte) error {
+	var f, err = z.zf.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(content)
+	return err
+}
+
+func (z *zipArchiveWriter) Close() error {
+	return z.zf.Close()
+}
+
+func (t *tarArchiveWriter) WriteDir(name string) error {
+	return t.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     name + "/",
+		Mode:     0755,
+		ModTime:  t.now,
+	})
+}
+
+func (t *tarArchiveWriter) WriteFile(name string, content []byte) error {
+	var err = t.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0644,
+		Size:     int64(len(content)),
+		ModTime:  t.now,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = t.tw.Write(content)
+	return err
+}
+
+func (t *tarArchiveWriter) Close() error {
+	if err := t.tw.Close(); err != nil {
+		return err
+	}
+	return t.gz.Close()
+}
+
+// WriteProjectArchive renders the project and writes it to w as a zip or tar.gz archive.
+// The files are placed in a directory named after the project,
+// together with the project configuration - just like WriteProject.
+//
+// BeforeCopy and AfterCopy steps are not executed.
+func (a *App) WriteProjectArchive(proj *config.Project, w io.Writer, format string) error {
+	var aw, err = newArchiveWriter(w, format)
+	if err != nil {
+		return err
+	}
+
+	err = a.RenderProject(proj, false, func(rf *RenderedFile) error {
+		var name = path.Join(proj.Name, rf.Path)
+		if rf.Source.IsDir() {
+			return aw.WriteDir(name)
+		}
+		return errors.Wrapf(aw.WriteFile(name, rf.Content), "failed to write archive entry %s", name)
+	})
+	if err != nil {
+		return err
+	}
+
+	cfg, err := yaml.Marshal(proj)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal config for project %s", proj.Name)
+	}
+
+	if err = aw.WriteFile(path.Join(proj.Name, config.PROJECT_CONFIG_NAME), cfg); err != nil {
+		return errors.Wrap(err, "failed to write archive entry for project config")
+	}
+
+	return aw.Close()
+}
+
+// GenerateProject writes the project to w as a zip or tar.gz archive.
+//
+// If runCommands is true, the project is written to a temporary directory with WriteProject,
+// which also executes the BeforeCopy and AfterCopy steps, and that directory is archived.
+// Otherwise the project is rendered in memory with WriteProjectArchive.
+func (a *App) GenerateProject(proj *config.Project, w io.Writer, format string, runCommands bool) error {
+	if !runCommands {
+		return a.WriteProjectArchive(proj, w, format)
+	}
+
+	var aw, err = newArchiveWriter(w, format)
+	if err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "quickgo-generate-*")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary directory")
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err = a.WriteProject(proj, tmpDir, false); err != nil {
+		return err
+	}
+
+	err = filepath.WalkDir(tmpDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == tmpDir {
+			return err
+		}
+
+		rel, err := filepath.Rel(tmpDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			return aw.WriteDir(rel)
+		}
+
+		if !d.Type().IsRegular() {
+			logger.Debugf("Skipping non-regular file %s in generated project", rel)
+			return nil
+		}
+
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		return aw.WriteFile(rel, content)
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to archive generated project")
+	}
+
+	return aw.Close()
+}
+
+// serveGenerate generates the named project with the context provided
+// in the request body and serves it as an archive.
+//
+// The context can be provided as a JSON body (see GenerateRequest), or as form values.
+// The archive format is taken from the "format" field or query parameter.
+func (a *App) serveGenerate(w http.ResponseWriter, r *http.Request, name string) {
+	var request = &GenerateRequest{
+		Context: make(map[string]any),
+		Format:  r.URL.Query().Get("format"),
+	}
+
+	var mediaType, _, _ = mime.ParseMediaType(r.Header.Get("Content-Type"))
+	r.Body = http.MaxBytesReader(w, r.Body, maxRenderRequestSize)
+
+	switch mediaType {
+	case "application/json":
+		if err := json.NewDecoder(r.Body).Decode(request); err != nil && err != io.EOF {
+			http.Error(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "Invalid form data: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if format := r.PostForm.Get("format"); format != "" {
+			request.Format = format
+		}
+
+		for k, v := range r.PostForm {
+			if k != "format" && len(v) > 0 {
+				request.Context[k] = v[0]
+			}
+		}
+	}
+
+	if _, err := newArchiveWriter(io.Discard, request.Format); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var proj, closeFiles, err = a.requestProject(r, name)
+	if err != nil {
+		logger.Errorf("Failed to read project '%s' for generation: %v", name, err)
+		http.Error(w, "Invalid project", http.StatusBadRequest)
+		return
+	}
+	defer closeFiles()
+
+	if proj.Context == nil {
+		proj.Context = make(map[string]any)
+	}
+	maps.Copy(proj.Context, request.Context)
+
+	var b = new(bytes.Buffer)
+	if err = a.GenerateProject(proj, b, request.Format, a.Config.AllowGenerateCommands); err != nil {
+		logger.Errorf("Failed to generate project '%s': %v", name, err)
+		http.Error(w, "Failed to generate project: "+err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	var contentType, ext = ArchiveContentType(request.Format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s%s", proj.Name, ext))
+	if _, err = b.WriteTo(w); err != nil {
+		logger.Errorf("Failed to write generated project '%s': %v", name, err)
+		return
+	}
+
+	a.metrics.generation(name, request.Format)
+	logger.Infof("%s Generated project '%s'", r.RemoteAddr, name)
+}
@@ -0,0 +1,138 @@
+package quickgo_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/command"
+)
+
+func TestGenerate(t *testing.T) {
+	var (
+		app  = newTestApp()
+		proj = newTestProject(t, map[string]string{
+			"README.md":           "{{ index .Context \"Greeting\" }} {{ .Name }}",
+			"{{ .Name }}/main.go": "package main",
+		})
+	)
+
+	proj.AfterCopy = &command.StepList{
+		Steps: []command.Step{
+			{Name: "Touch", Command: "touch", Args: []string{"$projectPath/generated.txt"}},
+		},
+	}
+
+	if err := app.WriteProjectConfig(proj); err != nil {
+		t.Fatal(err)
+	}
+
+	var server = httptest.NewServer(app.HttpHandler())
+	defer server.Close()
+
+	var generate = func(t *testing.T, contentType, body string, status int) []byte {
+		t.Helper()
+		var resp, err = http.Post(server.URL+"/projects/"+proj.Name+"/generate", contentType, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != status {
+			t.Fatalf("expected status %d, got %d: %s", status, resp.StatusCode, data)
+		}
+		return data
+	}
+
+	var readZip = func(t *testing.T, data []byte) map[string]string {
+		t.Helper()
+		var zr, err = zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var files = make(map[string]string)
+		for _, f := range zr.File {
+			var r, err = f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, _ := io.ReadAll(r)
+			r.Close()
+			files[f.Name] = string(content)
+		}
+		return files
+	}
+
+	t.Run("json zip", func(t *testing.T) {
+		var files = readZip(t, generate(t, "application/json", `{"context": {"Greeting": "Hi"}}`, http.StatusOK))
+		if files["test-project/README.md"] != "Hi test-project" {
+			t.Errorf("expected rendered README, got %q", files["test-project/README.md"])
+		}
+		if _, ok := files["test-project/test-project/main.go"]; !ok {
+			t.Errorf("expected rendered path in archive, got %v", files)
+		}
+		if _, ok := files["test-project/generated.txt"]; ok {
+			t.Errorf("expected AfterCopy steps not to be executed")
+		}
+	})
+
+	t.Run("form tar.gz", func(t *testing.T) {
+		var data = generate(t, "application/x-www-form-urlencoded", url.Values{
+			"Greeting": {"Hey"},
+			"format":   {"tar.gz"},
+		}.Encode(), http.StatusOK)
+
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var files = make(map[string]string)
+		var tr = tar.NewReader(gz)
+		for {
+			var hdr, err = tr.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			content, _ := io.ReadAll(tr)
+			files[hdr.Name] = string(content)
+		}
+
+		if files["test-project/README.md"] != "Hey test-project" {
+			t.Errorf("expected rendered README, got %q", files["test-project/README.md"])
+		}
+	})
+
+	t.Run("commands allowed", func(t *testing.T) {
+		app.Config.AllowGenerateCommands = true
+		defer func() { app.Config.AllowGenerateCommands = false }()
+
+		var files = readZip(t, generate(t, "application/json", `{"context": {"Greeting": "Hi"}}`, http.StatusOK))
+		if _, ok := files["test-project/generated.txt"]; !ok {
+			t.Errorf("expected AfterCopy steps to be executed, got %v", files)
+		}
+		if files["test-project/README.md"] != "Hi test-project" {
+			t.Errorf("expected rendered README, got %q", files["test-project/README.md"])
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		generate(t, "application/json", `{"format": "rar"}`, http.StatusBadRequest)
+		generate(t, "application/json", `{`, http.StatusBadRequest)
+	})
+}
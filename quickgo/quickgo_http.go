@@ -1,88 +1,143 @@
 package quickgo
 
 import (
-	"archive/zip"
 	"bytes"
 	"fmt"
 	html_template "html/template"
 	"io"
 	"net/http"
-	"os"
+	"net/url"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Nigel2392/goldcrest"
+	"github.com/Nigel2392/quickgo/v2/quickgo/auth"
 	"github.com/Nigel2392/quickgo/v2/quickgo/config"
+	"github.com/Nigel2392/quickgo/v2/quickgo/highlight"
 	"github.com/Nigel2392/quickgo/v2/quickgo/logger"
 	"github.com/Nigel2392/quickgo/v2/quickgo/quickfs"
+	"github.com/Nigel2392/quickgo/v2/quickgo/registry"
+	"github.com/Nigel2392/quickgo/v2/quickgo/search"
+	"github.com/Nigel2392/quickgo/v2/quickgo/store"
 	"github.com/pkg/errors"
 )
 
-func copyToZipfile(z *zip.Writer, name string, absPath string) error {
-	var f, err = os.Open(absPath)
-	if err != nil {
-		return errors.Wrapf(err, "failed to open '%s'", absPath)
-	}
-	defer f.Close()
-
-	w, err := z.Create(name)
-	if err != nil {
-		return errors.Wrapf(err, "failed to create '%s'", name)
+func (a *App) HttpHandler() http.Handler {
+	if a.metrics == nil {
+		a.metrics = newServerMetrics()
 	}
 
-	_, err = io.Copy(w, f)
-	return err
-
-}
-
-func (a *App) HttpHandler() http.Handler {
 	var mux = http.NewServeMux()
 	mux.Handle("/", &LogHandler{
-		Handler: http.HandlerFunc(a.serveIndex),
-		Where:   "index",
-		Level:   logger.InfoLevel,
+		Handler:  http.HandlerFunc(a.serveIndex),
+		Where:    "index",
+		Level:    logger.InfoLevel,
+		Observer: a.metrics,
 	})
 	mux.Handle("/projects/", &LogHandler{
 		Handler: http.StripPrefix(
 			"/projects/",
 			http.HandlerFunc(a.serveProjects),
 		),
-		Where: "projects",
-		Level: logger.InfoLevel,
+		Where:    "projects",
+		Level:    logger.InfoLevel,
+		Observer: a.metrics,
 	})
 	mux.Handle("/config/", &LogHandler{
 		Handler: http.StripPrefix(
 			"/config/",
 			http.HandlerFunc(a.serveProjectConfig),
 		),
-		Where: "project config",
-		Level: logger.InfoLevel,
+		Where:    "project config",
+		Level:    logger.InfoLevel,
+		Observer: a.metrics,
+	})
+	mux.Handle("/icon/", &LogHandler{
+		Handler: http.StripPrefix(
+			"/icon/",
+			http.HandlerFunc(a.serveIcon),
+		),
+		Where:    "project icon",
+		Level:    logger.DebugLevel,
+		Observer: a.metrics,
+	})
+	mux.Handle("/search", &LogHandler{
+		Handler:  http.HandlerFunc(a.serveSearch),
+		Where:    "search",
+		Level:    logger.InfoLevel,
+		Observer: a.metrics,
+	})
+	mux.Handle("/preview/", &LogHandler{
+		Handler: http.StripPrefix(
+			"/preview/",
+			http.HandlerFunc(a.servePreview),
+		),
+		Where:    "project preview",
+		Level:    logger.InfoLevel,
+		Observer: a.metrics,
+	})
+	mux.Handle(registry.PathPrefix, &LogHandler{
+		Handler: http.StripPrefix(
+			registry.PathPrefix,
+			http.HandlerFunc(a.serveRegistry),
+		),
+		Where:    "registry",
+		Level:    logger.InfoLevel,
+		Observer: a.metrics,
+	})
+	mux.Handle(APIPathPrefix, &LogHandler{
+		Handler: http.StripPrefix(
+			APIPathPrefix,
+			http.HandlerFunc(a.serveAPI),
+		),
+		Where:    "api",
+		Level:    logger.InfoLevel,
+		Observer: a.metrics,
+	})
+	mux.Handle("/metrics", &LogHandler{
+		Handler:  http.HandlerFunc(a.serveMetrics),
+		Where:    "metrics",
+		Level:    logger.DebugLevel,
+		Observer: a.metrics,
 	})
 	mux.Handle("/static/", &LogHandler{
-		Handler: http.StripPrefix("/static/", http.FileServer(http.FS(staticFS))),
-		Where:   "static files",
-		Level:   logger.DebugLevel,
+		Handler:  http.StripPrefix("/static/", http.FileServer(http.FS(a.staticFS()))),
+		Where:    "static files",
+		Level:    logger.DebugLevel,
+		Observer: a.metrics,
 	})
 	mux.Handle("/favicon.ico", &LogHandler{
-		Handler: http.HandlerFunc(a.serveFavicon),
-		Where:   "favicon",
-		Level:   logger.DebugLevel,
+		Handler:  http.HandlerFunc(a.serveFavicon),
+		Where:    "favicon",
+		Level:    logger.DebugLevel,
+		Observer: a.metrics,
 	})
-	return a.middleware(mux)
+
+	// Health checks bypass authentication and the server hooks.
+	var root = http.NewServeMux()
+	root.HandleFunc("/healthz", a.serveHealth)
+	root.HandleFunc("/readyz", a.serveReady)
+	root.Handle("/", a.middleware(mux))
+	return root
 }
 
 func (a *App) serveIndex(w http.ResponseWriter, r *http.Request) {
 
-	var projects, err = a.ListProjectObjects()
+	var projects, err = a.listProjects(a.requestStore(r))
 	if err != nil {
 		logger.Errorf("Failed to list projects: %v", err)
 		http.Error(w, "Failed to list projects", http.StatusInternalServerError)
 		return
 	}
 
+	// Only show the projects with the tag from the query, if any.
+	var tag = strings.TrimSpace(r.URL.Query().Get("tag"))
 	var ctx = &ProjectTemplateContext{
-		ObjectList: projects,
+		ObjectList: FilterProjectsByTag(projects, tag),
+		Tag:        tag,
 	}
 
 	if err = a.executeServeTemplate(w, "index.tmpl", ctx); err != nil {
@@ -100,41 +155,50 @@ func (a *App) serveProjects(w http.ResponseWriter, r *http.Request) {
 	)
 
 	var pathParts = strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if r.Method == http.MethodPost && len(pathParts) == 2 && pathParts[1] == "generate" {
+		a.serveGenerate(w, r, pathParts[0])
+		return
+	}
+
 	if r.URL.Query().Get("download") != "" && len(pathParts) == 1 {
 
-		var (
-			projName   = pathParts[0]
-			projPath   = GetProjectDirectoryPath(projName, true)
-			configFile = filepath.Join(projPath, config.PROJECT_CONFIG_NAME)
-			projectZip = filepath.Join(projPath, config.PROJECT_ZIP_NAME)
-		)
+		var projName = pathParts[0]
+		var tpl, err = a.requestStore(r).Get(projName)
+		if err != nil {
+			logger.Errorf("Failed to open project '%s' for export: %v", projName, err)
+			http.Error(w, "Invalid project", http.StatusBadRequest)
+			return
+		}
+		defer tpl.Close()
 
 		w.Header().Set("Content-Type", "application/zip")
 		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.zip", projName))
 
-		var zf = zip.NewWriter(w)
-		defer zf.Close()
-
-		logger.Debugf("Adding '%s' to zip for export", configFile)
-		if err = copyToZipfile(zf, config.PROJECT_CONFIG_NAME, configFile); err != nil {
-			logger.Errorf("Failed to copy '%s' to zip: %v", configFile, err)
-			http.Error(w, "Failed to copy to zip", http.StatusInternalServerError)
-			return
-		}
-
-		logger.Debugf("Adding '%s' to zip for export", projectZip)
-		if err = copyToZipfile(zf, config.PROJECT_ZIP_NAME, projectZip); err != nil {
-			logger.Errorf("Failed to copy '%s' to zip: %v", projectZip, err)
-			http.Error(w, "Failed to copy to zip", http.StatusInternalServerError)
+		logger.Debugf("Writing export archive for project '%s'", projName)
+		if err = store.WriteExport(w, tpl); err != nil {
+			logger.Errorf("Failed to write export archive for project '%s': %v", projName, err)
+			http.Error(w, "Failed to write export archive", http.StatusInternalServerError)
 			return
 		}
 
+		a.metrics.download(projName, DownloadExport)
 		logger.Infof("%s Downloaded project '%s'", r.RemoteAddr, projName)
 		return
 	}
 
 	logger.Debugf("Reading project '%s'", pathParts[0])
-	proj, closeFiles, err := a.ReadProjectConfig(pathParts[0])
+	entry, err := a.cachedProject(r, pathParts[0])
+	if err != nil {
+		logger.Errorf("Failed to read project '%s': %v", pathParts[0], err)
+		http.Error(w, "Invalid project", http.StatusBadRequest)
+		return
+	}
+
+	if a.notModified(w, r, entry) {
+		return
+	}
+
+	proj, closeFiles, err := a.openCachedProject(r, entry)
 	if err != nil {
 		logger.Errorf("Failed to read project '%s': %v", pathParts[0], err)
 		http.Error(w, "Invalid project", http.StatusBadRequest)
@@ -170,6 +234,11 @@ func (a *App) serveProjects(w http.ResponseWriter, r *http.Request) {
 		context.Dir = dir
 		context.ObjectList = FileObjects
 
+		// Render the README on the project's landing page.
+		if dir == proj.Root {
+			context.Markdown = a.renderReadme(dir)
+		}
+
 		logger.Debugf("Serving directory '%s' in project '%s'", dir.Name, proj.Name)
 
 		if err = a.executeServeTemplate(w, "dir.tmpl", &context); err != nil {
@@ -203,6 +272,16 @@ func (a *App) serveProjects(w http.ResponseWriter, r *http.Request) {
 	context.File = file
 	context.Content = content
 
+	if highlight.IsMarkdown(file.Name) && r.URL.Query().Get("source") == "" {
+		if context.Markdown, err = highlight.Markdown(b.Bytes()); err != nil {
+			logger.Warnf("Failed to render markdown file '%s': %v", file.GetPath(), err)
+		}
+	}
+
+	if context.Markdown == "" {
+		context.Lines = highlight.Highlight(file.Name, content, proj.DelimLeft, proj.DelimRight)
+	}
+
 	logger.Debugf("Serving file '%s' in project '%s'", file.Name, proj.Name)
 
 	if err = a.executeServeTemplate(w, "file.tmpl", &context); err != nil {
@@ -218,22 +297,25 @@ func (a *App) serveProjectConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var configFile, err = os.ReadFile(
-		GetProjectDirectoryPath(path.Join(pathParts[0], config.PROJECT_CONFIG_NAME), true),
-	)
+	var entry, err = a.cachedProject(r, pathParts[0])
 	if err != nil {
 		logger.Errorf("Failed to open project '%s': %v", pathParts[0], err)
 		http.Error(w, "Failed to open project", http.StatusInternalServerError)
 		return
 	}
 
+	if a.notModified(w, r, entry) {
+		return
+	}
+
 	var ctx = &ProjectTemplateContext{
 		// Fake for breadcrumbs
 		Dir: &quickfs.FSDirectory{
 			Name: pathParts[0],
 		},
 		// Config YAML data
-		Content: string(configFile),
+		Content: string(entry.Config),
+		Lines:   highlight.Highlight(config.PROJECT_CONFIG_NAME, string(entry.Config), "", ""),
 	}
 
 	if err = a.executeServeTemplate(w, "file.tmpl", ctx); err != nil {
@@ -242,10 +324,35 @@ func (a *App) serveProjectConfig(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// renderReadme renders the first markdown README file in the directory.
+// It returns an empty string if the directory has no README.
+func (a *App) renderReadme(dir *quickfs.FSDirectory) html_template.HTML {
+	for el := dir.Files.Front(); el != nil; el = el.Next() {
+		var file = el.Value
+		if !strings.HasPrefix(strings.ToLower(file.Name), "readme") || !highlight.IsMarkdown(file.Name) {
+			continue
+		}
+
+		var b = new(bytes.Buffer)
+		if _, err := io.Copy(b, file); err != nil {
+			logger.Warnf("Failed to read README '%s': %v", file.GetPath(), err)
+			return ""
+		}
+
+		var html, err = highlight.Markdown(b.Bytes())
+		if err != nil {
+			logger.Warnf("Failed to render README '%s': %v", file.GetPath(), err)
+			return ""
+		}
+		return html
+	}
+	return ""
+}
+
 func (a *App) serveFavicon(w http.ResponseWriter, r *http.Request) {
 	// Write out the favicon file.
 	// No need to log the happy path here, quite boring.
-	var f, err = staticFS.Open("quickgo.png")
+	var f, err = a.staticFS().Open("quickgo.png")
 	if err != nil {
 		logger.Errorf("Failed to open 'quickgo.png': %v", err)
 		http.Error(w, "Failed to open 'quickgo.png'", http.StatusInternalServerError)
@@ -268,6 +375,20 @@ func (a *App) middleware(next http.Handler) http.Handler {
 			}
 		}()
 
+		var principal, err = a.authenticate(r)
+		if err != nil {
+			logger.Warnf("%s Failed to authenticate on path '%s': %v", r.RemoteAddr, r.URL.Path, err)
+			a.writeUnauthorized(w, r, "Invalid credentials")
+			return
+		}
+
+		if principal == nil && a.authRequired() {
+			a.writeUnauthorized(w, r, "Authentication required")
+			return
+		}
+
+		r = r.WithContext(auth.NewContext(r.Context(), principal))
+
 		for _, hook := range goldcrest.Get[AppServeHook](HookQuickGoServer) {
 			if served, err := hook(a, w, r); err != nil {
 				logger.Errorf("Failed to serve: %v", err)
@@ -283,15 +404,57 @@ func (a *App) middleware(next http.Handler) http.Handler {
 	})
 }
 
-type LogHandler struct {
-	Handler http.Handler
-	Level   logger.LogLevel
-	Where   string
-}
+type (
+	// LogHandler writes an access log line for every request served by the handler.
+	LogHandler struct {
+		Handler  http.Handler
+		Level    logger.LogLevel
+		Where    string
+		Observer RequestObserver
+	}
+
+	// RequestObserver is notified of every request served by a LogHandler.
+	RequestObserver interface {
+		ObserveRequest(where string, r *http.Request, status int, size int64, duration time.Duration)
+	}
+
+	// responseRecorder records the status code and size of a response.
+	responseRecorder struct {
+		http.ResponseWriter
+		status int
+		size   int64
+	}
+)
 
 func (h *LogHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	var s = fmt.Sprintf("Serving %s to %s on path '%s'", h.Where, r.RemoteAddr, r.URL.Path)
-	switch h.Level {
+	var (
+		start    = time.Now()
+		recorder = &responseRecorder{ResponseWriter: w}
+	)
+
+	h.Handler.ServeHTTP(recorder, r)
+
+	var duration = time.Since(start)
+	if recorder.status == 0 {
+		recorder.status = http.StatusOK
+	}
+
+	if h.Observer != nil {
+		h.Observer.ObserveRequest(h.Where, r, recorder.status, recorder.size, duration)
+	}
+
+	var s = fmt.Sprintf(
+		"method=%s path=%s status=%d bytes=%d duration=%s remote=%s handler=%s",
+		r.Method, strconv.Quote(r.URL.RequestURI()), recorder.status, recorder.size,
+		duration.Round(time.Microsecond), r.RemoteAddr, strconv.Quote(h.Where),
+	)
+
+	var level = h.Level
+	if recorder.status >= http.StatusInternalServerError {
+		level = logger.ErrorLevel
+	}
+
+	switch level {
 	case logger.DebugLevel:
 		logger.Debug(s)
 	case logger.InfoLevel:
@@ -301,7 +464,27 @@ func (h *LogHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	case logger.ErrorLevel:
 		logger.Error(s)
 	}
-	h.Handler.ServeHTTP(w, r)
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	var n, err = r.ResponseWriter.Write(b)
+	r.size += int64(n)
+	return n, err
+}
+
+// Unwrap returns the original response writer, for use with http.ResponseController.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
 }
 
 type ProjectTemplateContext struct {
@@ -311,12 +494,37 @@ type ProjectTemplateContext struct {
 	Parent     *quickfs.FSDirectory
 	ObjectList any
 	Content    string
+	Preview    *ProjectPreview
+
+	// The tag the project list is filtered on.
+	Tag string
+
+	// The highlighted lines of Content.
+	Lines []highlight.Line
+
+	// Rendered markdown, shown instead of the highlighted lines.
+	Markdown html_template.HTML
 }
 
-func (a *App) executeServeTemplate(w http.ResponseWriter, name string, context *ProjectTemplateContext) (err error) {
-	var tpl = html_template.New("base")
+// executeServeTemplate renders the named page template with the context.
+// The page templates are parsed once and cloned for every request.
+func (a *App) executeServeTemplate(w io.Writer, name string, context *ProjectTemplateContext) (err error) {
+	var tpl *html_template.Template
+	if tpl, err = a.pageTemplate(name); err != nil {
+		return err
+	}
+
+	if tpl, err = tpl.Clone(); err != nil {
+		return errors.Wrapf(err, "failed to clone template %s", name)
+	}
+
+	return tpl.Funcs(a.templateFuncs(context)).ExecuteTemplate(w, name, context)
+}
 
-	tpl = tpl.Funcs(html_template.FuncMap{
+// templateFuncs returns the functions available in the page templates.
+// The context may be nil when the templates are only parsed.
+func (a *App) templateFuncs(context *ProjectTemplateContext) html_template.FuncMap {
+	return html_template.FuncMap{
 		"ObjectURL": func(fl quickfs.FileLike) string {
 			return filepath.ToSlash(path.Join(
 				"/projects",
@@ -330,12 +538,36 @@ func (a *App) executeServeTemplate(w http.ResponseWriter, name string, context *
 				project.Name,
 			))
 		},
+		"PreviewURL": func(project *config.Project) string {
+			return filepath.ToSlash(path.Join(
+				"/preview",
+				project.Name,
+			))
+		},
 		"ConfigURL": func(project *config.Project) string {
 			return filepath.ToSlash(path.Join(
 				"/config",
 				project.Name,
 			))
 		},
+		"IconURL": func(project *config.Project) string {
+			return filepath.ToSlash(path.Join(
+				"/icon",
+				project.Name,
+			))
+		},
+		"TagURL": func(tag string) string {
+			return "/?" + url.Values{"tag": {tag}}.Encode()
+		},
+		"FileURL": func(project, file string) string {
+			return path.Join(
+				"/projects",
+				project,
+				file,
+			)
+		},
+		"Highlight":    search.Highlight,
+		"HighlightCSS": highlight.CSS,
 		"FileSize": func(size int64) string {
 			f_size := float64(size)
 			if f_size < 1024 {
@@ -352,11 +584,5 @@ func (a *App) executeServeTemplate(w http.ResponseWriter, name string, context *
 			f_size = f_size / 1024
 			return fmt.Sprintf("%.1f GB", f_size)
 		},
-	})
-
-	if tpl, err = tpl.ParseFS(embedFS, append(a.Patterns, fmt.Sprintf("_templates/%s", name))...); err != nil {
-		return errors.Wrapf(err, "failed to parse template %s", name)
 	}
-
-	return tpl.ExecuteTemplate(w, name, context)
 }
@@ -0,0 +1,72 @@
+package quickgo_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFileViewer(t *testing.T) {
+	var (
+		app  = newTestApp()
+		proj = newTestProject(t, map[string]string{
+			"README.md": "# {{ .Name }}\n\nA *test* project.\n",
+			"main.go":   "package main\n\nfunc {{ .Name }}() {}\n",
+		})
+	)
+
+	if err := app.WriteProjectConfig(proj); err != nil {
+		t.Fatal(err)
+	}
+
+	var server = httptest.NewServer(app.HttpHandler())
+	defer server.Close()
+
+	var fetch = func(t *testing.T, path string) string {
+		t.Helper()
+		var resp, err = http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", resp.StatusCode, data)
+		}
+		return string(data)
+	}
+
+	var tests = []struct {
+		name     string
+		path     string
+		expected []string
+	}{
+		{"landing page", "/projects/test-project", []string{"<em>test</em>", "main.go"}},
+		{"markdown", "/projects/test-project/README.md", []string{"<em>test</em>", "View source"}},
+		{"markdown source", "/projects/test-project/README.md?source=1", []string{`id="L3"`, `class="quickgo-tpl"`}},
+		{"source", "/projects/test-project/main.go", []string{
+			`<a href="#L3">3</a>`,
+			`<span class="kn">package</span>`,
+			`<span class="quickgo-tpl">{{ .Name }}</span>`,
+		}},
+		{"config", "/config/test-project", []string{`id="L1"`}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var body = fetch(t, test.path)
+			for _, expected := range test.expected {
+				if !strings.Contains(body, expected) {
+					t.Errorf("expected %q in response", expected)
+				}
+			}
+		})
+	}
+}
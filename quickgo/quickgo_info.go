@@ -0,0 +1,199 @@
+package quickgo
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/config"
+	"github.com/Nigel2392/quickgo/v2/quickgo/logger"
+	"github.com/Nigel2392/quickgo/v2/quickgo/quickfs"
+	"github.com/pkg/errors"
+)
+
+type (
+	// ProjectSummary describes a saved project in listings.
+	ProjectSummary struct {
+		Name        string   `json:"name" yaml:"name"`
+		Description string   `json:"description" yaml:"description"`
+		Tags        []string `json:"tags" yaml:"tags"`
+		Author      string   `json:"author" yaml:"author"`
+		Homepage    string   `json:"homepage" yaml:"homepage"`
+		MinVersion  string   `json:"minVersion" yaml:"minVersion"`
+		Icon        string   `json:"icon" yaml:"icon"`
+		Visibility  string   `json:"visibility" yaml:"visibility"`
+	}
+
+	// ProjectInfo is a detailed description of a saved project.
+	ProjectInfo struct {
+		ProjectSummary `yaml:",inline"`
+
+		// The size of the stored archive in bytes.
+		Size int64 `json:"size" yaml:"size"`
+
+		// The last time the project was saved.
+		ModTime time.Time `json:"modTime" yaml:"modTime"`
+
+		// The number of files in the project.
+		Files int `json:"files" yaml:"files"`
+
+		// The default context for the project templates.
+		Context map[string]any `json:"context" yaml:"context"`
+
+		// The commands of the project, sorted by name.
+		Commands []*CommandInfo `json:"commands" yaml:"commands"`
+	}
+
+	// CommandInfo describes a global or project command.
+	CommandInfo struct {
+		Name        string `json:"name" yaml:"name"`
+		Description string `json:"description" yaml:"description"`
+		Script      string `json:"script,omitempty" yaml:"script,omitempty"` // The path of the JS file of a project script.
+	}
+)
+
+// NewProjectSummary returns the summary of a project.
+func NewProjectSummary(proj *config.Project) *ProjectSummary {
+	var summary = &ProjectSummary{
+		Name:        proj.Name,
+		Description: proj.Description,
+		Tags:        proj.Tags,
+		Author:      proj.Author,
+		Homepage:    proj.Homepage,
+		MinVersion:  proj.MinVersion,
+		Icon:        proj.Icon,
+		Visibility:  proj.Visibility,
+	}
+
+	// Always output a list, also when the project has no tags.
+	if summary.Tags == nil {
+		summary.Tags = make([]string, 0)
+	}
+
+	return summary
+}
+
+// ProjectCommands returns the commands of a project, sorted by name.
+func ProjectCommands(proj *config.Project) []*CommandInfo {
+	var commands = make([]*CommandInfo, 0, len(proj.Commands))
+	for name, cmd := range proj.Commands {
+		commands = append(commands, &CommandInfo{
+			Name:        name,
+			Description: cmd.Description,
+		})
+	}
+
+	slices.SortFunc(commands, func(a, b *CommandInfo) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return commands
+}
+
+// ProjectInfo returns a detailed description of the named, saved project.
+func (a *App) ProjectInfo(name string) (*ProjectInfo, error) {
+	var tpl, err = a.Store.Get(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get project %s", name)
+	}
+	defer tpl.Close()
+
+	proj, err := config.ReadYaml[config.Project](
+		bytes.NewReader(tpl.Config),
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load project config %s", name)
+	}
+
+	zr, err := zip.NewReader(tpl.Archive, tpl.Size)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read archive of project %s", name)
+	}
+
+	var info = &ProjectInfo{
+		ProjectSummary: *NewProjectSummary(proj),
+		Size:           tpl.Size,
+		ModTime:        tpl.ModTime,
+		Context:        proj.Context,
+		Commands:       ProjectCommands(proj),
+	}
+
+	if info.Context == nil {
+		info.Context = make(map[string]any)
+	}
+
+	for _, f := range zr.File {
+		if !f.FileInfo().IsDir() {
+			info.Files++
+		}
+	}
+
+	return info, nil
+}
+
+// FilterProjectsByTag returns the projects which have the given tag.
+// All projects are returned if the tag is empty.
+func FilterProjectsByTag(projects []*config.Project, tag string) []*config.Project {
+	if tag == "" {
+		return projects
+	}
+
+	var filtered = make([]*config.Project, 0, len(projects))
+	for _, proj := range projects {
+		if proj.HasTag(tag) {
+			filtered = append(filtered, proj)
+		}
+	}
+	return filtered
+}
+
+// serveIcon serves the icon of a project.
+// Only files with an image content type are served.
+func (a *App) serveIcon(w http.ResponseWriter, r *http.Request) {
+	var name = strings.Trim(r.URL.Path, "/")
+	var entry, err = a.cachedProject(r, name)
+	if err != nil {
+		logger.Errorf("Failed to read project '%s' for icon: %v", name, err)
+		http.Error(w, "Invalid project", http.StatusBadRequest)
+		return
+	}
+
+	if a.notModified(w, r, entry) {
+		return
+	}
+
+	proj, closeFiles, err := a.openCachedProject(r, entry)
+	if err != nil {
+		logger.Errorf("Failed to read project '%s' for icon: %v", name, err)
+		http.Error(w, "Invalid project", http.StatusBadRequest)
+		return
+	}
+	defer closeFiles()
+
+	var contentType = mime.TypeByExtension(path.Ext(proj.Icon))
+	if proj.Icon == "" || !strings.HasPrefix(contentType, "image/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	_, fileLike, err := proj.Root.FindWithParent(strings.Split(proj.Icon, "/"))
+	if err != nil || fileLike.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Icons are user content, do not let browsers guess the type or run scripts in (svg) images.
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+
+	if _, err = io.Copy(w, fileLike.(*quickfs.FSFile)); err != nil {
+		logger.Errorf("Failed to write icon of project '%s': %v", name, err)
+	}
+}
@@ -0,0 +1,155 @@
+package quickgo_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo"
+	"github.com/Nigel2392/quickgo/v2/quickgo/command"
+	"github.com/Nigel2392/quickgo/v2/quickgo/config"
+)
+
+func TestProjectInfo(t *testing.T) {
+	var (
+		app  = newTestApp()
+		proj = newTestProject(t, map[string]string{
+			"README.md":   "{{ .Name }}",
+			"src/main.go": "package main",
+			"icon.png":    "\x89PNG",
+		})
+	)
+
+	proj.Description = "A test project"
+	proj.Tags = []string{"Go", "test"}
+	proj.Author = "QuickGo"
+	proj.Icon = "icon.png"
+	proj.Commands = config.ProjectCommandMap{
+		"build": {Description: "Build the project", Steps: &command.StepList{}},
+		"apply": {Steps: &command.StepList{}},
+	}
+
+	if err := app.WriteProjectConfig(proj); err != nil {
+		t.Fatal(err)
+	}
+
+	var info, err = app.ProjectInfo(proj.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Description != proj.Description || info.Author != proj.Author || info.Icon != proj.Icon {
+		t.Errorf("expected metadata of the project, got %+v", info)
+	}
+
+	if info.Files != 3 {
+		t.Errorf("expected 3 files, got %d", info.Files)
+	}
+
+	if info.Size == 0 || info.ModTime.IsZero() {
+		t.Errorf("expected size and modtime to be set, got %d and %v", info.Size, info.ModTime)
+	}
+
+	if len(info.Commands) != 2 || info.Commands[0].Name != "apply" || info.Commands[1].Description != "Build the project" {
+		t.Errorf("expected sorted commands, got %+v", info.Commands)
+	}
+
+	if _, err = app.ProjectInfo("missing"); err == nil {
+		t.Error("expected an error for a missing project")
+	}
+}
+
+func TestFilterProjectsByTag(t *testing.T) {
+	var projects = []*config.Project{
+		{Name: "a", Tags: []string{"go", "web"}},
+		{Name: "b", Tags: []string{"Go"}},
+		{Name: "c"},
+	}
+
+	var tests = map[string][]string{
+		"":    {"a", "b", "c"},
+		"go":  {"a", "b"},
+		"WEB": {"a"},
+		"js":  {},
+	}
+
+	for tag, expected := range tests {
+		var filtered = quickgo.FilterProjectsByTag(projects, tag)
+		var names = make([]string, 0, len(filtered))
+		for _, proj := range filtered {
+			names = append(names, proj.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(expected, ",") {
+			t.Errorf("tag %q: expected %v, got %v", tag, expected, names)
+		}
+	}
+}
+
+func TestProjectMetadataHTTP(t *testing.T) {
+	var (
+		app  = newTestApp()
+		proj = newTestProject(t, map[string]string{
+			"icon.svg":  "<svg></svg>",
+			"notes.txt": "not an image",
+		})
+	)
+
+	proj.Description = "A test project"
+	proj.Tags = []string{"go"}
+	proj.Icon = "icon.svg"
+
+	if err := app.WriteProjectConfig(proj); err != nil {
+		t.Fatal(err)
+	}
+
+	var server = httptest.NewServer(app.HttpHandler())
+	defer server.Close()
+
+	var get = func(t *testing.T, path string) (*http.Response, string) {
+		t.Helper()
+		var resp, err = http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var body, _ = io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	t.Run("index", func(t *testing.T) {
+		var _, body = get(t, "/?tag=go")
+		for _, expected := range []string{"A test project", `href="/?tag=go"`, `src="/icon/test-project"`} {
+			if !strings.Contains(body, expected) {
+				t.Errorf("expected index to contain %q", expected)
+			}
+		}
+
+		if _, body = get(t, "/?tag=js"); strings.Contains(body, "A test project") {
+			t.Error("expected project to be filtered out of the index")
+		}
+	})
+
+	t.Run("icon", func(t *testing.T) {
+		var resp, body = get(t, "/icon/test-project")
+		if resp.StatusCode != http.StatusOK || body != "<svg></svg>" {
+			t.Fatalf("expected icon, got %d: %s", resp.StatusCode, body)
+		}
+
+		if resp.Header.Get("Content-Type") != "image/svg+xml" || resp.Header.Get("X-Content-Type-Options") != "nosniff" {
+			t.Errorf("unexpected icon headers: %v", resp.Header)
+		}
+	})
+
+	t.Run("not an image", func(t *testing.T) {
+		proj.Icon = "notes.txt"
+		if err := app.WriteProjectConfig(proj); err != nil {
+			t.Fatal(err)
+		}
+
+		if resp, _ := get(t, "/icon/test-project"); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,266 @@
+package quickgo
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/command"
+	"github.com/Nigel2392/quickgo/v2/quickgo/config"
+	"github.com/Nigel2392/quickgo/v2/quickgo/js"
+	"github.com/Nigel2392/quickgo/v2/quickgo/logger"
+	"github.com/pkg/errors"
+)
+
+// jsQuickGo implements the quickgo module of JS commands.
+//
+// Paths are resolved like the paths of the fs module, and checked against the permissions of the script.
+// Project steps and commands run shell commands, so their executables must be allowed to run.
+type jsQuickGo struct {
+	app       *App
+	perms     *js.Permissions
+	fs        *jsFS
+	targetDir string
+}
+
+func newJSQuickGo(app *App, perms *js.Permissions, fs *jsFS, targetDir string) *jsQuickGo {
+	return &jsQuickGo{
+		app:       app,
+		perms:     perms,
+		fs:        fs,
+		targetDir: targetDir,
+	}
+}
+
+// module returns the values and functions of the quickgo module, environ are the arguments of the script.
+func (q *jsQuickGo) module(environ map[string]any) map[string]any {
+	var (
+		projectName string
+		projectPath string
+	)
+	if q.app.ProjectConfig != nil {
+		projectName = q.app.ProjectConfig.Name
+		projectPath = getTargetDirectory(q.targetDir)
+	}
+
+	var module = map[string]any{
+		"project":     jsSnapshot(q.app.ProjectConfig),
+		"config":      jsSnapshot(publicConfig(q.app.Config)),
+		"environ":     environ,
+		"projectName": projectName,
+		"projectPath": projectPath,
+		"projectLocked": func() bool {
+			return config.IsLocked(q.targetDir) == nil
+		},
+		"render":       q.render,
+		"renderFile":   q.renderFile,
+		"listProjects": q.listProjects,
+		"useProject":   q.useProject,
+		"saveProject":  q.saveProject,
+		"runCommand":   q.runCommand,
+	}
+
+	// The app can do anything, only trusted scripts get access to it.
+	if q.perms.All {
+		module["app"] = q.app
+	}
+
+	return module
+}
+
+// jsSnapshot returns a copy of v as plain data, without any of its methods.
+// Go objects must not be handed to scripts directly,
+// their methods would let a script run steps and commands without the permission to do so.
+func jsSnapshot(v any) any {
+	var data, err = json.Marshal(v)
+	if err != nil {
+		logger.Errorf("failed to copy %T for JS: %s", v, err)
+		return nil
+	}
+
+	var snapshot any
+	if err = json.Unmarshal(data, &snapshot); err != nil {
+		logger.Errorf("failed to copy %T for JS: %s", v, err)
+		return nil
+	}
+	return snapshot
+}
+
+// publicConfig returns a copy of the configuration without its secrets.
+func publicConfig(cfg *config.QuickGo) *config.QuickGo {
+	if cfg == nil {
+		return nil
+	}
+
+	var public = *cfg
+	public.Auth = nil
+	public.PublishTokens = nil
+	public.Remotes = make(map[string]*config.Remote, len(cfg.Remotes))
+	for name, remote := range cfg.Remotes {
+		public.Remotes[name] = &config.Remote{URL: remote.URL}
+	}
+	return &public
+}
+
+// render executes the Go template with the context as its data.
+// The delimiters default to {{ and }}, they can be changed with { delimLeft, delimRight } as options.
+func (q *jsQuickGo) render(source string, ctx map[string]any, options map[string]any) (string, error) {
+	var (
+		left, _  = options["delimLeft"].(string)
+		right, _ = options["delimRight"].(string)
+		tpl      = template.New("render").Delims(left, right)
+		b        = new(strings.Builder)
+	)
+
+	if _, err := tpl.Parse(source); err != nil {
+		return "", errors.Wrap(err, "failed to parse template")
+	}
+
+	if err := tpl.Execute(b, ctx); err != nil {
+		return "", errors.Wrap(err, "failed to execute template")
+	}
+
+	return b.String(), nil
+}
+
+// renderFile renders the template in the file, see render.
+func (q *jsQuickGo) renderFile(path string, ctx map[string]any, options map[string]any) (string, error) {
+	var source, err = q.fs.readTextFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	rendered, err := q.render(source, ctx, options)
+	return rendered, errors.Wrapf(err, "failed to render %s", path)
+}
+
+// listProjects returns the summaries of the saved projects.
+func (q *jsQuickGo) listProjects() ([]*ProjectSummary, error) {
+	var projects, err = q.app.ListProjectObjects()
+	if err != nil {
+		return nil, err
+	}
+
+	var summaries = make([]*ProjectSummary, len(projects))
+	for i, proj := range projects {
+		summaries[i] = NewProjectSummary(proj)
+	}
+	return summaries, nil
+}
+
+// useProject writes the saved project to <dir>/<name>, like quickgo -use.
+// The context is added to the context of the project, the path of the new project is returned.
+func (q *jsQuickGo) useProject(name string, dir string, ctx map[string]any) (string, error) {
+	if dir == "" {
+		dir = "."
+	}
+
+	if err := q.fs.checkWrite(dir); err != nil {
+		return "", err
+	}
+
+	var proj, closeFiles, err = q.app.ReadProjectConfig(name)
+	if err != nil {
+		return "", err
+	}
+	defer closeFiles()
+
+	if err = checkSteps(q.perms, proj.BeforeCopy, proj.AfterCopy); err != nil {
+		return "", err
+	}
+
+	if proj.Context == nil {
+		proj.Context = make(map[string]any)
+	}
+	maps.Copy(proj.Context, ctx)
+
+	if err = q.app.WriteProject(proj, q.fs.path(dir), false); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(q.fs.path(dir), proj.Name), nil
+}
+
+// saveProject saves the project in the directory, like quickgo -save.
+// The directory must contain a project configuration, the name of the saved project is returned.
+func (q *jsQuickGo) saveProject(dir string) (string, error) {
+	if dir == "" {
+		dir = "."
+	}
+
+	if err := q.fs.checkRead(dir); err != nil {
+		return "", err
+	}
+
+	var directory = q.fs.path(dir)
+	var proj, err = config.LoadYaml[config.Project](
+		filepath.Join(directory, config.PROJECT_CONFIG_NAME),
+	)
+	if err != nil && os.IsNotExist(err) {
+		return "", errors.Wrapf(config.ErrProjectMissing, "no %s in %s", config.PROJECT_CONFIG_NAME, directory)
+	} else if err != nil {
+		return "", errors.Wrapf(err, "failed to load project config in %s", directory)
+	}
+
+	if err = proj.Validate(); err != nil {
+		return "", err
+	}
+
+	if err = proj.Load(directory); err != nil {
+		return "", errors.Wrapf(err, "failed to load project files in %s", directory)
+	}
+
+	if err = q.app.WriteProjectConfig(proj); err != nil {
+		return "", err
+	}
+
+	logger.Debugf("Project '%s' saved from '%s'", proj.Name, directory)
+	return proj.Name, nil
+}
+
+// runCommand runs the named command of the current project, the env is added to the arguments of the command.
+func (q *jsQuickGo) runCommand(name string, env map[string]any) error {
+	if q.app.ProjectConfig == nil {
+		return config.ErrProjectMissing
+	}
+
+	var cmd, err = q.app.ProjectConfig.Command(name, nil)
+	if err != nil {
+		return errors.Wrapf(err, "command '%s'", name)
+	}
+
+	if err = checkSteps(q.perms, cmd.Steps); err != nil {
+		return err
+	}
+
+	var commandEnv = map[string]any{
+		"projectPath": getTargetDirectory(q.targetDir),
+	}
+	maps.Copy(commandEnv, env)
+
+	return q.app.ProjectConfig.ExecCommand(name, commandEnv)
+}
+
+// checkSteps returns an error if the script is not allowed to run the executables of the steps.
+// JS steps are run with their own permissions.
+func checkSteps(perms *js.Permissions, lists ...*command.StepList) error {
+	for _, list := range lists {
+		if list == nil {
+			continue
+		}
+
+		for _, step := range list.Steps {
+			if step.IsJS() {
+				continue
+			}
+
+			if err := perms.CheckRun(step.Command); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package quickgo_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/command"
+	"github.com/Nigel2392/quickgo/v2/quickgo/config"
+	"github.com/Nigel2392/quickgo/v2/quickgo/js"
+)
+
+func TestQuickGoScriptAPI(t *testing.T) {
+	var (
+		app  = newTestApp()
+		proj = newTestProject(t, map[string]string{
+			"README.md": `{{ .Name }}: {{ index .Context "Greeting" }}`,
+		})
+	)
+
+	if err := app.WriteProjectConfig(proj); err != nil {
+		t.Fatal(err)
+	}
+
+	// The files of the project are written to the store, the files below are only used by the script.
+	for name, content := range map[string]string{
+		"greeting.tmpl":      "<< .greeting >>, << .name >>!",
+		"other/quickgo.yaml": "name: other-project\n",
+		"other/src/main.go":  "package main",
+	} {
+		if err := os.MkdirAll(filepath.Dir(name), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	proj.Commands = config.ProjectCommandMap{
+		"touch": {
+			Steps: &command.StepList{
+				Steps: []command.Step{{Name: "touch", Command: "touch", Args: []string{"$file"}}},
+			},
+		},
+	}
+	proj.Permissions = &js.Permissions{Read: []string{"."}, Write: []string{"."}, Run: []string{"touch"}}
+	app.ProjectConfig = proj
+
+	var values, err = app.ExecJSStep(command.Step{Name: "compose", Script: `
+		const rendered = quickgo.render("{{ .greeting }}, {{ .name }}!", { greeting: "Hello", name: "World" });
+		const renderedFile = quickgo.renderFile("greeting.tmpl", { greeting: "Hi", name: "file" }, { delimLeft: "<<", delimRight: ">>" });
+
+		const path = quickgo.useProject("test-project", "out", { Greeting: "Hey" });
+		const readme = fs.readTextFile(fs.joinPath(path, "README.md"));
+
+		quickgo.runCommand("touch", { file: "touched.txt" });
+		const saved = quickgo.saveProject("other");
+
+		return {
+			rendered: rendered,
+			renderedFile: renderedFile,
+			readme: readme,
+			touched: fs.exists("touched.txt"),
+			saved: saved,
+			projects: quickgo.listProjects().map((p) => p.name).sort().join(","),
+			hasApp: quickgo.app !== undefined,
+		};
+	`}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expected = map[string]any{
+		"rendered":     "Hello, World!",
+		"renderedFile": "Hi, file!",
+		"readme":       "test-project: Hey",
+		"touched":      true,
+		"saved":        "other-project",
+		"projects":     "other-project,test-project",
+		"hasApp":       false,
+	}
+
+	for key, value := range expected {
+		if values[key] != value {
+			t.Errorf("expected %s to be %v, got %v", key, value, values[key])
+		}
+	}
+
+	t.Run("denied", func(t *testing.T) {
+		proj.Permissions = &js.Permissions{Read: []string{"."}}
+		defer func() { proj.Permissions = nil }()
+
+		for _, script := range []string{
+			`quickgo.useProject("test-project", "out")`,
+			`quickgo.runCommand("touch", { file: "denied.txt" })`,
+			`quickgo.renderFile("/etc/hostname", {})`,
+		} {
+			var _, err = app.ExecJSStep(command.Step{Name: "denied", Script: script}, nil)
+			if err == nil || !strings.Contains(err.Error(), "permission denied") {
+				t.Errorf("expected %s to be denied, got %v", script, err)
+			}
+		}
+	})
+
+	t.Run("snapshot", func(t *testing.T) {
+		app.Config = &config.QuickGo{
+			Auth:    &config.Auth{Tokens: []*config.AuthToken{{Name: "ci", Token: "secret"}}},
+			Remotes: map[string]*config.Remote{"origin": {URL: "https://example.com", Token: "secret"}},
+		}
+		defer func() { app.Config = &config.QuickGo{} }()
+
+		var values, err = app.ExecJSStep(command.Step{Name: "snapshot", Script: `
+			const touch = quickgo.project.commands.touch;
+			return {
+				name: quickgo.project.name,
+				methods: [
+					typeof quickgo.project.execCommand,
+					typeof quickgo.project.command,
+					typeof touch.execute,
+					typeof touch.Steps.execute,
+				].join(","),
+				auth: quickgo.config.Auth === null || quickgo.config.Auth === undefined,
+				remote: quickgo.config.Remotes.origin.URL + " " + (quickgo.config.Remotes.origin.Token || ""),
+			};
+		`}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var expected = map[string]any{
+			"name":    "test-project",
+			"methods": "undefined,undefined,undefined,undefined",
+			"auth":    true,
+			"remote":  "https://example.com ",
+		}
+
+		for key, value := range expected {
+			if values[key] != value {
+				t.Errorf("expected %s to be %v, got %v", key, value, values[key])
+			}
+		}
+	})
+
+	t.Run("no permissions", func(t *testing.T) {
+		for _, script := range []string{
+			`quickgo.project.execCommand("touch", { file: "snapshot.txt" })`,
+			`quickgo.project.commands.touch.execute({ file: "snapshot.txt" })`,
+			`quickgo.project.commands.touch.Steps.steps[0].command = "sh"; quickgo.runCommand("touch", { file: "snapshot.txt" })`,
+		} {
+			var _, err = app.ExecJSStep(command.Step{Name: "no-permissions", Script: script}, nil)
+			if err == nil {
+				t.Errorf("expected %s to fail", script)
+			}
+		}
+
+		if _, err := os.Stat("snapshot.txt"); !os.IsNotExist(err) {
+			t.Errorf("expected snapshot.txt not to be created, got %v", err)
+		}
+
+		if step := proj.Commands["touch"].Steps.Steps[0]; step.Command != "touch" {
+			t.Errorf("expected the command of the step not to change, got %s", step.Command)
+		}
+	})
+
+	t.Run("run command", func(t *testing.T) {
+		proj.Commands["rm"] = &config.ProjectCommand{
+			Steps: &command.StepList{
+				Steps: []command.Step{{Name: "remove", Command: "rm", Args: []string{"greeting.tmpl"}}},
+			},
+		}
+		proj.Permissions = &js.Permissions{Run: []string{"touch"}}
+		defer func() {
+			delete(proj.Commands, "rm")
+			proj.Permissions = nil
+		}()
+
+		var _, err = app.ExecJSStep(command.Step{Name: "run-command", Script: `quickgo.runCommand("rm", {})`}, nil)
+
+		var permErr *js.PermissionError
+		if !errors.As(err, &permErr) {
+			t.Fatalf("expected a permission error, got %v", err)
+		}
+
+		if permErr.Kind != "run" || permErr.Target != "rm" {
+			t.Errorf("expected run access to rm to be denied, got %s access to %s", permErr.Kind, permErr.Target)
+		}
+
+		if _, err := os.Stat("greeting.tmpl"); err != nil {
+			t.Errorf("expected greeting.tmpl to exist, got %v", err)
+		}
+	})
+}
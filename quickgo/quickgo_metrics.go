@@ -0,0 +1,122 @@
+package quickgo
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/auth"
+	"github.com/Nigel2392/quickgo/v2/quickgo/metrics"
+)
+
+const (
+	DownloadExport   = "export"   // The export archive was downloaded from the web UI.
+	DownloadRegistry = "registry" // The export archive was downloaded through the registry, e.g. with `quickgo -use remote/name`.
+	DownloadPreview  = "preview"  // The rendered project was downloaded from the preview page.
+)
+
+// serverMetrics are the metrics of the server, served on /metrics.
+type serverMetrics struct {
+	registry    *metrics.Registry
+	requests    *metrics.Counter
+	errors      *metrics.Counter
+	duration    *metrics.Histogram
+	downloads   *metrics.Counter
+	generations *metrics.Counter
+}
+
+func newServerMetrics() *serverMetrics {
+	var m = &serverMetrics{
+		registry: metrics.NewRegistry(),
+		requests: metrics.NewCounter(
+			"quickgo_http_requests_total",
+			"Number of HTTP requests by handler, method and status code.",
+			"handler", "method", "code",
+		),
+		errors: metrics.NewCounter(
+			"quickgo_http_errors_total",
+			"Number of HTTP requests which resulted in a 4xx or 5xx status code.",
+			"handler", "code",
+		),
+		duration: metrics.NewHistogram(
+			"quickgo_http_request_duration_seconds",
+			"Latency of HTTP requests by handler.",
+			metrics.DefaultBuckets,
+			"handler",
+		),
+		downloads: metrics.NewCounter(
+			"quickgo_template_downloads_total",
+			"Number of template downloads by template and source (export, registry or preview).",
+			"template", "source",
+		),
+		generations: metrics.NewCounter(
+			"quickgo_template_generations_total",
+			"Number of projects generated on the server by template and archive format.",
+			"template", "format",
+		),
+	}
+
+	m.registry.Register(
+		m.requests,
+		m.errors,
+		m.duration,
+		m.downloads,
+		m.generations,
+	)
+
+	return m
+}
+
+// ObserveRequest records the request in the HTTP metrics.
+func (m *serverMetrics) ObserveRequest(where string, r *http.Request, status int, size int64, duration time.Duration) {
+	if m == nil {
+		return
+	}
+
+	var code = strconv.Itoa(status)
+	m.requests.Inc(where, requestMethod(r), code)
+	m.duration.Observe(duration.Seconds(), where)
+	if status >= 400 {
+		m.errors.Inc(where, code)
+	}
+}
+
+// download records a successful download of a template.
+func (m *serverMetrics) download(template, source string) {
+	if m != nil {
+		m.downloads.Inc(template, source)
+	}
+}
+
+// generation records a successful generation of a project.
+func (m *serverMetrics) generation(template, format string) {
+	if m == nil {
+		return
+	}
+	if format == "" {
+		format = ArchiveZip
+	}
+	m.generations.Inc(template, format)
+}
+
+// requestMethod returns the method of the request for use as a label.
+// Unknown methods are grouped, so clients cannot create new label values.
+func requestMethod(r *http.Request) string {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return r.Method
+	}
+	return "OTHER"
+}
+
+// serveMetrics serves the metrics in the Prometheus text format.
+// If authentication is configured, only authenticated principals can see the metrics;
+// they contain the names of all templates.
+func (a *App) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	if a.Auth != nil && auth.FromContext(r.Context()) == nil {
+		a.writeUnauthorized(w, r, "Authentication required")
+		return
+	}
+	a.metrics.registry.ServeHTTP(w, r)
+}
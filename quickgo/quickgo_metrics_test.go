@@ -0,0 +1,79 @@
+package quickgo_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/auth"
+)
+
+func TestMetrics(t *testing.T) {
+	var (
+		app  = newTestApp()
+		proj = newTestProject(t, map[string]string{
+			"README.txt": "Hello",
+		})
+	)
+
+	if err := app.WriteProjectConfig(proj); err != nil {
+		t.Fatal(err)
+	}
+
+	var server = httptest.NewServer(app.HttpHandler())
+	defer server.Close()
+
+	var get = func(t *testing.T, path, token string) (int, string) {
+		t.Helper()
+		var req, err = http.NewRequest(http.MethodGet, server.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var body, _ = io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	get(t, "/projects/test-project?download=1", "")
+	get(t, "/projects/does-not-exist", "")
+
+	var code, body = get(t, "/metrics", "")
+	if code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", code, body)
+	}
+
+	for _, line := range []string{
+		`quickgo_template_downloads_total{template="test-project",source="export"} 1`,
+		`quickgo_http_requests_total{handler="projects",method="GET",code="200"} 1`,
+		`quickgo_http_errors_total{handler="projects",code="400"} 1`,
+		`quickgo_http_request_duration_seconds_count{handler="projects"} 2`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("expected metrics to contain %q, got:\n%s", line, body)
+		}
+	}
+
+	t.Run("auth", func(t *testing.T) {
+		app.Auth = auth.TokenAuthenticator{
+			"reader": {Name: "reader"},
+		}
+		defer func() { app.Auth = nil }()
+
+		if code, _ := get(t, "/metrics", ""); code != http.StatusUnauthorized {
+			t.Errorf("expected status 401 without token, got %d", code)
+		}
+		if code, _ := get(t, "/metrics", "reader"); code != http.StatusOK {
+			t.Errorf("expected status 200 with token, got %d", code)
+		}
+	})
+}
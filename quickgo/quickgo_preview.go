@@ -0,0 +1,159 @@
+package quickgo
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/config"
+	"github.com/Nigel2392/quickgo/v2/quickgo/logger"
+	"github.com/Nigel2392/quickgo/v2/quickgo/quickfs"
+)
+
+type (
+	// ProjectPreview is the template context for the rendered preview of a project.
+	ProjectPreview struct {
+		// Form fields for the keys of the project's context.
+		Fields []*PreviewField
+
+		// The rendered files of the project.
+		Files []*RenderedFile
+
+		// The encoded form values, used for the download link.
+		Query string
+
+		// The error which occurred while rendering the project, if any.
+		Error string
+	}
+
+	// PreviewField is a form field for a single context key.
+	PreviewField struct {
+		Name  string
+		Value string
+	}
+)
+
+// servePreview renders the whole project in memory with the context provided in the query parameters.
+//
+//	GET <name>              -> form for the context and the rendered files
+//	GET <name>?download=1   -> the rendered project as a zip archive
+func (a *App) servePreview(w http.ResponseWriter, r *http.Request) {
+	var name = strings.Trim(r.URL.Path, "/")
+	if name == "" || strings.Contains(name, "/") {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+
+	var proj, closeFiles, err = a.requestProject(r, name)
+	if err != nil {
+		logger.Errorf("Failed to read project '%s' for preview: %v", name, err)
+		http.Error(w, "Invalid project", http.StatusBadRequest)
+		return
+	}
+	defer closeFiles()
+
+	var query = r.URL.Query()
+	var download = query.Get("download") != ""
+	query.Del("download")
+
+	var preview = &ProjectPreview{
+		Fields: newPreviewFields(proj, query),
+		Query:  query.Encode(),
+	}
+
+	applyContextValues(proj, query)
+
+	if download {
+		var b = new(bytes.Buffer)
+		if err = a.WriteProjectArchive(proj, b, ArchiveZip); err != nil {
+			logger.Errorf("Failed to render project '%s' for download: %v", name, err)
+			http.Error(w, "Failed to render project: "+err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.zip", proj.Name))
+		if _, err = b.WriteTo(w); err != nil {
+			logger.Errorf("Failed to write rendered project '%s': %v", name, err)
+			return
+		}
+
+		a.metrics.download(name, DownloadPreview)
+		logger.Infof("%s Downloaded rendered project '%s'", r.RemoteAddr, name)
+		return
+	}
+
+	var status = http.StatusOK
+	err = a.RenderProject(proj, false, func(rf *RenderedFile) error {
+		if !rf.Source.IsDir() {
+			preview.Files = append(preview.Files, rf)
+		}
+		return nil
+	})
+	if err != nil {
+		preview.Files = nil
+		preview.Error = err.Error()
+		status = http.StatusUnprocessableEntity
+	}
+
+	var ctx = &ProjectTemplateContext{
+		Project: proj,
+		// Fake for breadcrumbs
+		Dir: &quickfs.FSDirectory{
+			Name: proj.Name,
+		},
+		Preview: preview,
+	}
+
+	// The page is rendered before the status is written, so a failure can still be reported.
+	var b = new(bytes.Buffer)
+	if err = a.executeServeTemplate(b, "preview.tmpl", ctx); err != nil {
+		logger.Errorf("Failed to render preview of project '%s': %v", name, err)
+		http.Error(w, "Failed to render preview", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if _, err = b.WriteTo(w); err != nil {
+		logger.Errorf("Failed to write preview of project '%s': %v", name, err)
+	}
+}
+
+// newPreviewFields returns a form field for every key in the project's context, in sorted order.
+// The values default to the project's context, and are overridden by the query parameters.
+func newPreviewFields(proj *config.Project, query url.Values) []*PreviewField {
+	var fields = make([]*PreviewField, 0, len(proj.Context))
+	for k, v := range proj.Context {
+		var field = &PreviewField{
+			Name:  k,
+			Value: fmt.Sprint(v),
+		}
+		if query.Has(k) {
+			field.Value = query.Get(k)
+		}
+		fields = append(fields, field)
+	}
+
+	slices.SortFunc(fields, func(a, b *PreviewField) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return fields
+}
+
+// applyContextValues merges the values into the project's context.
+func applyContextValues(proj *config.Project, values url.Values) {
+	if proj.Context == nil {
+		proj.Context = make(map[string]any)
+	}
+
+	for k, v := range values {
+		if len(v) > 0 {
+			proj.Context[k] = v[0]
+		}
+	}
+}
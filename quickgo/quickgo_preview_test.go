@@ -0,0 +1,151 @@
+package quickgo_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo"
+)
+
+func TestPreview(t *testing.T) {
+	var (
+		app  = newTestApp()
+		proj = newTestProject(t, map[string]string{
+			"README.md":           "{{ index .Context \"Greeting\" }} {{ .Name }}",
+			"{{ .Name }}/main.go": "package main",
+		})
+	)
+
+	if err := app.WriteProjectConfig(proj); err != nil {
+		t.Fatal(err)
+	}
+
+	var server = httptest.NewServer(app.HttpHandler())
+	defer server.Close()
+
+	var fetch = func(t *testing.T, query string) []byte {
+		t.Helper()
+		var resp, err = http.Get(server.URL + "/preview/" + proj.Name + "?" + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", resp.StatusCode, data)
+		}
+		return data
+	}
+
+	t.Run("render", func(t *testing.T) {
+		var body = string(fetch(t, "Greeting=Hi"))
+		for _, expected := range []string{
+			`name="Greeting" value="Hi"`,
+			"Hi test-project",
+			"test-project/main.go",
+		} {
+			if !strings.Contains(body, expected) {
+				t.Errorf("expected preview to contain %q", expected)
+			}
+		}
+	})
+
+	t.Run("download", func(t *testing.T) {
+		var data = fetch(t, "Greeting=Hi&download=1")
+		var zr, err = zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var files = make(map[string]string)
+		for _, f := range zr.File {
+			var r, err = f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			content, _ := io.ReadAll(r)
+			r.Close()
+			files[f.Name] = string(content)
+		}
+
+		if files["test-project/README.md"] != "Hi test-project" {
+			t.Errorf("expected rendered README, got %q", files["test-project/README.md"])
+		}
+
+		if _, ok := files["test-project/test-project/main.go"]; !ok {
+			t.Errorf("expected rendered path in archive, got %v", files)
+		}
+
+		if _, ok := files["test-project/quickgo.yaml"]; !ok {
+			t.Errorf("expected project config in archive")
+		}
+	})
+}
+
+func TestPreviewErrors(t *testing.T) {
+	var (
+		app  = newTestApp()
+		proj = newTestProject(t, map[string]string{
+			"README.md": "{{ .Missing",
+		})
+		theme = t.TempDir()
+	)
+
+	if err := app.WriteProjectConfig(proj); err != nil {
+		t.Fatal(err)
+	}
+
+	// The preview page of the theme fails after part of it was rendered.
+	var page = `{{ template "base" . }}{{ define "content" }}partial page{{ call .Project.Name }}{{ end }}`
+	if err := os.WriteFile(filepath.Join(theme, "preview.tmpl"), []byte(page), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var themed = newTestApp()
+	themed.Store = app.Store
+
+	var err error
+	if themed.TemplateFS, err = quickgo.ThemeFS(theme); err != nil {
+		t.Fatal(err)
+	}
+
+	var get = func(t *testing.T, app *quickgo.App) (int, string) {
+		t.Helper()
+		var server = httptest.NewServer(app.HttpHandler())
+		defer server.Close()
+
+		var resp, err = http.Get(server.URL + "/preview/" + proj.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var body, _ = io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	t.Run("render error", func(t *testing.T) {
+		var code, body = get(t, app)
+		if code != http.StatusUnprocessableEntity || !strings.Contains(body, "README.md") {
+			t.Errorf("expected the preview page with the error, got %d: %s", code, body)
+		}
+	})
+
+	t.Run("page error", func(t *testing.T) {
+		var code, body = get(t, themed)
+		if code != http.StatusInternalServerError || strings.Contains(body, "partial page") {
+			t.Errorf("expected only an error, got %d: %s", code, body)
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package quickgo
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/prompt"
+)
+
+// The prefix of the environment variables with answers to prompts, followed by the name of the prompt in upper case.
+const PromptEnvPrefix = "QUICKGO_"
+
+// stdin is shared by the prompts of all scripts, so input which is buffered by one prompt is not lost for the next.
+var stdin = bufio.NewReader(os.Stdin)
+
+// newPrompter returns the prompter of a script, which asks the questions on the terminal.
+// Prompts are not interactive if stdin is not a terminal, or if the CI environment variable is set.
+func newPrompter(args map[string]any) *prompt.Prompter {
+	var p = prompt.New(
+		stdin,
+		os.Stderr,
+		IsTerminal(os.Stdin) && os.Getenv("CI") == "",
+		promptAnswers(args),
+	)
+	p.HideInput = prompt.Stty(os.Stdin)
+	return p
+}
+
+// promptAnswers looks up answers to prompts in the arguments of the script,
+// then in the environment variables with PromptEnvPrefix.
+func promptAnswers(args map[string]any) prompt.Answers {
+	return func(name string) (string, bool) {
+		if v, ok := args[name]; ok {
+			return promptValue(v), true
+		}
+		return os.LookupEnv(PromptEnvPrefix + promptEnvName(name))
+	}
+}
+
+// promptEnvName returns the name in upper case, with characters other than letters and digits replaced by underscores.
+func promptEnvName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return '_'
+		}
+		return unicode.ToUpper(r)
+	}, name)
+}
+
+func promptValue(v any) string {
+	if list, ok := v.([]any); ok {
+		var values = make([]string, len(list))
+		for i, item := range list {
+			values[i] = fmt.Sprint(item)
+		}
+		return strings.Join(values, ",")
+	}
+	return fmt.Sprint(v)
+}
+
+// promptModule returns the functions of the prompt module of JS commands.
+//
+// The options of a prompt are its name, to look up the answer with when not interactive, and its default.
+func promptModule(p *prompt.Prompter) map[string]any {
+	var question = func(message string, options []string, opts map[string]any) prompt.Question {
+		var q = prompt.Question{Message: message, Options: options}
+		q.Name, _ = opts["name"].(string)
+		if v, ok := opts["default"]; ok && v != nil {
+			q.Default = []string{promptValue(v)}
+		}
+		return q
+	}
+
+	return map[string]any{
+		"text": func(message string, opts map[string]any) (string, error) {
+			return p.Text(question(message, nil, opts))
+		},
+		"password": func(message string, opts map[string]any) (string, error) {
+			return p.Password(question(message, nil, opts))
+		},
+		"confirm": func(message string, opts map[string]any) (bool, error) {
+			return p.Confirm(question(message, nil, opts))
+		},
+		"select": func(message string, options []string, opts map[string]any) (string, error) {
+			return p.Select(question(message, options, opts))
+		},
+		"multiSelect": func(message string, options []string, opts map[string]any) ([]string, error) {
+			return p.MultiSelect(question(message, options, opts))
+		},
+	}
+}
@@ -0,0 +1,55 @@
+package quickgo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/command"
+)
+
+func TestPromptScripts(t *testing.T) {
+	var app = newTestApp()
+
+	t.Setenv("QUICKGO_DB_NAME", "from-env")
+	t.Setenv("CI", "true")
+
+	var values, err = app.ExecJSStep(command.Step{Name: "prompt", Script: `
+		return {
+			name: prompt.text("Name", { name: "name" }),
+			license: prompt.text("License", { name: "license", default: "MIT" }),
+			dbName: prompt.text("Database name", { name: "db-name" }),
+			docker: prompt.confirm("Use docker?", { name: "docker", default: false }),
+			db: prompt.select("Database", ["sqlite", "postgres"], { name: "db" }),
+			features: prompt.multiSelect("Features", ["auth", "api", "docs"], { name: "features", default: ["auth", "docs"] }).join(","),
+			token: prompt.password("Token", { name: "token" }),
+		};
+	`}, map[string]any{
+		"name":  "my-app",
+		"db":    "2",
+		"token": "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expected = map[string]any{
+		"name":     "my-app",
+		"license":  "MIT",
+		"dbName":   "from-env",
+		"docker":   false,
+		"db":       "postgres",
+		"features": "auth,docs",
+		"token":    "secret",
+	}
+
+	for key, value := range expected {
+		if values[key] != value {
+			t.Errorf("expected %s to be %v, got %v", key, value, values[key])
+		}
+	}
+
+	_, err = app.ExecJSStep(command.Step{Name: "missing", Script: `prompt.text("Version", { name: "version" });`}, nil)
+	if err == nil || !strings.Contains(err.Error(), "provide version as an answer") {
+		t.Errorf("expected an error for a missing answer, got %v", err)
+	}
+}
@@ -0,0 +1,239 @@
+package quickgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/auth"
+	"github.com/Nigel2392/quickgo/v2/quickgo/config"
+	"github.com/Nigel2392/quickgo/v2/quickgo/logger"
+	"github.com/Nigel2392/quickgo/v2/quickgo/registry"
+	"github.com/Nigel2392/quickgo/v2/quickgo/store"
+	"github.com/pkg/errors"
+)
+
+// serveRegistry serves the JSON registry protocol used by remote quickgo clients.
+//
+//	GET      templates                  -> list all templates
+//	GET      templates/<name>           -> metadata and versions of a template
+//	GET      templates/<name>/archive   -> export archive of a template
+//	PUT/POST templates/<name>           -> publish the export archive of a template
+func (a *App) serveRegistry(w http.ResponseWriter, r *http.Request) {
+	var pathParts = strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if pathParts[0] != "templates" || len(pathParts) > 3 {
+		writeJSONError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	if (r.Method == http.MethodPut || r.Method == http.MethodPost) && len(pathParts) == 2 {
+		a.serveRegistryPublish(w, r, pathParts[1])
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeJSONError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	switch {
+	case len(pathParts) == 1:
+		a.serveRegistryList(w, r)
+	case len(pathParts) == 2:
+		a.serveRegistryTemplate(w, r, pathParts[1])
+	case pathParts[2] == "archive":
+		a.serveRegistryArchive(w, r, pathParts[1])
+	default:
+		writeJSONError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+func (a *App) serveRegistryList(w http.ResponseWriter, r *http.Request) {
+	var templates, err = a.requestStore(r).List()
+	if err != nil {
+		logger.Errorf("Failed to list templates for registry: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to list templates")
+		return
+	}
+
+	var list = &registry.TemplateList{
+		Templates: make([]*registry.Template, 0, len(templates)),
+	}
+
+	for _, tpl := range templates {
+		list.Templates = append(list.Templates, &registry.Template{
+			Name:    tpl.Name,
+			Size:    tpl.Size,
+			ModTime: tpl.ModTime,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (a *App) serveRegistryTemplate(w http.ResponseWriter, r *http.Request, name string) {
+	var tpl, ok = a.getRegistryTemplate(w, r, name)
+	if !ok {
+		return
+	}
+	defer tpl.Close()
+
+	var checksum, err = store.Checksum(tpl)
+	if err != nil {
+		logger.Errorf("Failed to compute checksum for template '%s': %v", name, err)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to read template")
+		return
+	}
+
+	// The store only keeps the latest version of a template.
+	writeJSON(w, http.StatusOK, &registry.Template{
+		Name:    tpl.Name,
+		Size:    tpl.Size,
+		ModTime: tpl.ModTime,
+		Version: checksum,
+		Versions: []registry.Version{{
+			Version: checksum,
+			Size:    tpl.Size,
+			ModTime: tpl.ModTime,
+		}},
+	})
+}
+
+func (a *App) serveRegistryArchive(w http.ResponseWriter, r *http.Request, name string) {
+	var tpl, ok = a.getRegistryTemplate(w, r, name)
+	if !ok {
+		return
+	}
+	defer tpl.Close()
+
+	w.Header().Set("Content-Type", registry.ArchiveContentType)
+	w.Header().Set("Content-Disposition", "attachment; filename="+name+".zip")
+
+	var cw = &countingWriter{Writer: w}
+	if err := store.WriteExport(cw, tpl); err != nil {
+		logger.Errorf("Failed to write export archive for template '%s': %v", name, err)
+
+		// Once part of the archive is sent, the status and headers cannot be changed anymore.
+		if cw.n == 0 {
+			w.Header().Del("Content-Disposition")
+			writeJSONError(w, http.StatusInternalServerError, "Failed to write export archive")
+		}
+		return
+	}
+
+	a.metrics.download(name, DownloadRegistry)
+	logger.Infof("%s Downloaded template '%s' from registry", r.RemoteAddr, name)
+}
+
+func (a *App) serveRegistryPublish(w http.ResponseWriter, r *http.Request, name string) {
+	var principal = auth.FromContext(r.Context())
+	if principal == nil {
+		a.writeUnauthorized(w, r, "Publishing requires authentication")
+		return
+	}
+
+	if !principal.CanPublish() {
+		writeJSONError(w, http.StatusForbidden, "Publishing requires the publish permission")
+		return
+	}
+
+	if err := store.ValidateName(name); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "Invalid template name")
+		return
+	}
+
+	var body, err = io.ReadAll(http.MaxBytesReader(w, r.Body, registry.MaxPublishSize))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			writeJSONError(w, http.StatusRequestEntityTooLarge, "Export archive is too large")
+			return
+		}
+		writeJSONError(w, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+
+	cfg, archive, err := store.ReadExport(bytes.NewReader(body), int64(len(body)))
+	if err != nil && errors.Is(err, store.ErrExportTooLarge) {
+		writeJSONError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	} else if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, err = a.ImportProject(name, cfg, archive); err != nil {
+		if errors.Is(err, store.ErrExportTooLarge) {
+			writeJSONError(w, http.StatusRequestEntityTooLarge, err.Error())
+			return
+		}
+		if errors.Is(err, config.ErrProjectInvalid) {
+			writeJSONError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		logger.Errorf("Failed to publish template '%s': %v", name, err)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to store template")
+		return
+	}
+
+	meta, err := a.Store.Stat(name)
+	if err != nil {
+		logger.Errorf("Failed to stat published template '%s': %v", name, err)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to read template")
+		return
+	}
+
+	logger.Infof("%s Published template '%s' to registry as '%s'", r.RemoteAddr, name, principal.Name)
+
+	writeJSON(w, http.StatusCreated, &registry.Template{
+		Name:    meta.Name,
+		Size:    meta.Size,
+		ModTime: meta.ModTime,
+	})
+}
+
+func (a *App) getRegistryTemplate(w http.ResponseWriter, r *http.Request, name string) (*store.Template, bool) {
+	var tpl, err = a.requestStore(r).Get(name)
+	switch {
+	case errors.Is(err, store.ErrTemplateMissing):
+		writeJSONError(w, http.StatusNotFound, "Template not found")
+		return nil, false
+	case errors.Is(err, store.ErrTemplateName):
+		writeJSONError(w, http.StatusBadRequest, "Invalid template name")
+		return nil, false
+	case err != nil:
+		logger.Errorf("Failed to get template '%s' for registry: %v", name, err)
+		writeJSONError(w, http.StatusInternalServerError, "Failed to read template")
+		return nil, false
+	}
+	return tpl, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Errorf("Failed to encode JSON response: %v", err)
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, &registry.ErrorResponse{
+		Status:  status,
+		Message: message,
+	})
+}
+
+// countingWriter counts the bytes written to the underlying writer.
+type countingWriter struct {
+	io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	var n, err = w.Writer.Write(p)
+	w.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,281 @@
+package quickgo_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/auth"
+	"github.com/Nigel2392/quickgo/v2/quickgo/registry"
+	"github.com/Nigel2392/quickgo/v2/quickgo/store"
+)
+
+func TestRegistry(t *testing.T) {
+	var (
+		app  = newTestApp()
+		proj = newTestProject(t, map[string]string{
+			"README.md": "{{ .Name }}",
+		})
+	)
+
+	if err := app.WriteProjectConfig(proj); err != nil {
+		t.Fatal(err)
+	}
+
+	var server = httptest.NewServer(app.HttpHandler())
+	defer server.Close()
+
+	var client = registry.NewClient(server.URL)
+
+	templates, err := client.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(templates) != 1 || templates[0].Name != proj.Name {
+		t.Fatalf("expected 1 template named %q, got %v", proj.Name, templates)
+	}
+
+	info, err := client.Template(proj.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tpl, err := app.Store.Get(proj.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpl.Close()
+
+	checksum, err := store.Checksum(tpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Version != checksum || len(info.Versions) != 1 {
+		t.Errorf("expected version %q, got %q (%d versions)", checksum, info.Version, len(info.Versions))
+	}
+
+	var buf = new(bytes.Buffer)
+	if err = client.Download(proj.Name, buf); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, _, err := store.ReadExport(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(cfg, tpl.Config) {
+		t.Errorf("expected downloaded config to equal stored config")
+	}
+
+	var errResp *registry.ErrorResponse
+	if _, err = client.Template("missing"); !errors.As(err, &errResp) || errResp.Status != http.StatusNotFound {
+		t.Errorf("expected 404 error response, got %v", err)
+	}
+}
+
+func newExport(t *testing.T, cfg string, files map[string]string) *bytes.Buffer {
+	var archive = new(bytes.Buffer)
+	var zf = zip.NewWriter(archive)
+	for name, content := range files {
+		var w, err = zf.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zf.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var s = store.NewMemoryStore()
+	if err := s.Put("export", []byte(cfg), archive); err != nil {
+		t.Fatal(err)
+	}
+
+	var tpl, err = s.Get("export")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf = new(bytes.Buffer)
+	if err = store.WriteExport(buf, tpl); err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+func TestRegistryPublish(t *testing.T) {
+	var app = newTestApp()
+	app.Auth = auth.TokenAuthenticator{
+		"reader":    {Name: "reader"},
+		"publisher": {Name: "publisher", Permission: auth.PermissionPublish},
+	}
+
+	var server = httptest.NewServer(app.HttpHandler())
+	defer server.Close()
+
+	var (
+		client  = registry.NewClient(server.URL)
+		cfg     = "name: published\nexclude:\n  - '*node_modules*'\n"
+		errResp *registry.ErrorResponse
+	)
+
+	var tests = []struct {
+		name    string
+		token   string
+		project string
+		files   map[string]string
+		status  int
+	}{
+		{"missing token", "", "published", map[string]string{"README.md": "hi"}, http.StatusUnauthorized},
+		{"wrong token", "wrong", "published", map[string]string{"README.md": "hi"}, http.StatusUnauthorized},
+		{"read only", "reader", "published", map[string]string{"README.md": "hi"}, http.StatusForbidden},
+		{"name mismatch", "publisher", "other", map[string]string{"README.md": "hi"}, http.StatusUnprocessableEntity},
+		{"excluded", "publisher", "published", map[string]string{"node_modules/x.js": "x"}, http.StatusUnprocessableEntity},
+		{"path traversal", "publisher", "published", map[string]string{"../x.js": "x"}, http.StatusUnprocessableEntity},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client.Token = test.token
+
+			var _, err = client.Publish(test.project, newExport(t, cfg, test.files))
+			if !errors.As(err, &errResp) || errResp.Status != test.status {
+				t.Fatalf("expected status %d, got %v", test.status, err)
+			}
+		})
+	}
+
+	t.Run("too large", func(t *testing.T) {
+		// The archives are small, but decompress to more than the limit.
+		var maxSize = store.MaxExportSize
+		store.MaxExportSize = 1 << 20
+		defer func() { store.MaxExportSize = maxSize }()
+
+		client.Token = "publisher"
+
+		for name, export := range map[string]*bytes.Buffer{
+			"project files": newExport(t, cfg, map[string]string{"zeros.txt": strings.Repeat("\x00", 4<<20)}),
+			"configuration": newExport(t, cfg+"#"+strings.Repeat(" ", 4<<20)+"\n", map[string]string{"README.md": "hi"}),
+		} {
+			if export.Len() > 64<<10 {
+				t.Fatalf("expected a small export for the %s, got %d bytes", name, export.Len())
+			}
+
+			var _, err = client.Publish("published", export)
+			if !errors.As(err, &errResp) || errResp.Status != http.StatusRequestEntityTooLarge {
+				t.Errorf("expected status %d for the %s, got %v", http.StatusRequestEntityTooLarge, name, err)
+			}
+		}
+	})
+
+	if _, err := app.Store.Stat("published"); !errors.Is(err, store.ErrTemplateMissing) {
+		t.Fatalf("expected rejected templates not to be stored, got %v", err)
+	}
+
+	client.Token = "publisher"
+
+	published, err := client.Publish("published", newExport(t, cfg, map[string]string{"README.md": "hi"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if published.Name != "published" {
+		t.Errorf("expected %q, got %q", "published", published.Name)
+	}
+
+	if _, err = app.Store.Stat("published"); err != nil {
+		t.Fatalf("expected published template to be stored, got %v", err)
+	}
+}
+
+// brokenArchiveStore returns templates whose archive cannot be read.
+type brokenArchiveStore struct {
+	store.TemplateStore
+	config []byte
+}
+
+func (s *brokenArchiveStore) Get(name string) (*store.Template, error) {
+	var tpl, err = s.TemplateStore.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if s.config != nil {
+		tpl.Config = s.config
+	}
+	tpl.Archive = brokenReaderAt{}
+	return tpl, nil
+}
+
+type brokenReaderAt struct{}
+
+func (brokenReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	return 0, errors.New("broken archive")
+}
+
+func TestRegistryArchiveError(t *testing.T) {
+	var (
+		app  = newTestApp()
+		proj = newTestProject(t, map[string]string{
+			"README.md": "{{ .Name }}",
+		})
+	)
+
+	if err := app.WriteProjectConfig(proj); err != nil {
+		t.Fatal(err)
+	}
+
+	var broken = &brokenArchiveStore{TemplateStore: app.Store}
+	app.Store = broken
+
+	var server = httptest.NewServer(app.HttpHandler())
+	defer server.Close()
+
+	var get = func(t *testing.T) (*http.Response, string) {
+		t.Helper()
+		var resp, err = http.Get(server.URL + registry.PathPrefix + "templates/" + proj.Name + "/archive")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var body, _ = io.ReadAll(resp.Body)
+		return resp, string(body)
+	}
+
+	t.Run("before writing", func(t *testing.T) {
+		var resp, body = get(t)
+		if resp.StatusCode != http.StatusInternalServerError || !strings.Contains(body, "Failed to write export archive") {
+			t.Errorf("expected a JSON error, got %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("after writing", func(t *testing.T) {
+		// A configuration larger than the buffer of the zip writer is sent before the archive is read.
+		// It must not compress well, random hex digits are used for this.
+		var padding = make([]byte, 32<<10)
+		var rng = rand.New(rand.NewPCG(1, 2))
+		for i := range padding {
+			padding[i] = byte(rng.Uint32())
+		}
+		broken.config = []byte("name: test-project\n# " + hex.EncodeToString(padding) + "\n")
+		defer func() { broken.config = nil }()
+
+		var resp, body = get(t)
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != registry.ArchiveContentType {
+			t.Errorf("expected the headers of the archive, got %d: %v", resp.StatusCode, resp.Header)
+		}
+		if strings.Contains(body, "Failed to write export archive") {
+			t.Error("expected no JSON error after part of the archive was sent")
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package quickgo
+
+import (
+	"bytes"
+	"net/url"
+	"sort"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/config"
+	"github.com/Nigel2392/quickgo/v2/quickgo/logger"
+	"github.com/Nigel2392/quickgo/v2/quickgo/registry"
+	"github.com/Nigel2392/quickgo/v2/quickgo/store"
+	"github.com/pkg/errors"
+)
+
+// WriteConfig writes the global QuickGo configuration to the user's config directory.
+func (a *App) WriteConfig() error {
+	var configPath = GetQuickGoPath(config.QUICKGO_CONFIG_NAME)
+	logger.Debugf("Writing config file %s", configPath)
+	return config.WriteYaml(a.Config, configPath)
+}
+
+// AddRemote adds (or replaces) a remote quickgo server and saves the global configuration.
+// The token is optional and only required for private templates and publishing to the remote.
+func (a *App) AddRemote(name, remoteURL, token string) error {
+	if err := store.ValidateName(name); err != nil {
+		return errors.Wrapf(err, "invalid remote name '%s'", name)
+	}
+
+	var u, err = url.Parse(remoteURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("invalid remote URL '%s', expected http(s)://host[:port]", remoteURL)
+	}
+
+	if a.Config.Remotes == nil {
+		a.Config.Remotes = make(map[string]*config.Remote)
+	}
+
+	a.Config.Remotes[name] = &config.Remote{
+		URL:   remoteURL,
+		Token: token,
+	}
+
+	logger.Infof("Adding remote '%s' at %s", name, remoteURL)
+
+	return a.WriteConfig()
+}
+
+// RemoveRemote removes a remote quickgo server and saves the global configuration.
+func (a *App) RemoveRemote(name string) error {
+	if _, ok := a.Config.Remotes[name]; !ok {
+		return errors.Wrapf(config.ErrRemoteMissing, "remote '%s'", name)
+	}
+
+	delete(a.Config.Remotes, name)
+
+	logger.Infof("Removing remote '%s'", name)
+
+	return a.WriteConfig()
+}
+
+// ListRemotes returns the names of all configured remotes in sorted order.
+func (a *App) ListRemotes() []string {
+	var names = make([]string, 0, len(a.Config.Remotes))
+	for name := range a.Config.Remotes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RemoteClient returns a registry client for the named remote.
+func (a *App) RemoteClient(name string) (*registry.Client, error) {
+	var remote, ok = a.Config.Remotes[name]
+	if !ok {
+		return nil, errors.Wrapf(config.ErrRemoteMissing, "remote '%s'", name)
+	}
+	var client = registry.NewClient(remote.URL)
+	client.Token = remote.Token
+	return client, nil
+}
+
+// RemoteCache returns the store which caches templates fetched from the named remote.
+func (a *App) RemoteCache(remote string) store.TemplateStore {
+	return store.NewDiskStore(GetQuickGoPath(config.CACHE_DIR, remote))
+}
+
+// FetchRemoteProject makes sure the latest version of the named template
+// on the remote is available in the remote's local cache.
+//
+// If the remote cannot be reached but the template was cached before,
+// the cached version is used.
+func (a *App) FetchRemoteProject(remote, name string) error {
+	var client, err = a.RemoteClient(remote)
+	if err != nil {
+		return err
+	}
+
+	var cache = a.RemoteCache(remote)
+
+	info, err := client.Template(name)
+	if err != nil {
+		if _, statErr := cache.Stat(name); statErr == nil {
+			logger.Warnf("Failed to reach remote '%s', using cached '%s': %v", remote, name, err)
+			return nil
+		}
+		return errors.Wrapf(err, "failed to fetch '%s' from remote '%s'", name, remote)
+	}
+
+	if tpl, err := cache.Get(name); err == nil {
+		var checksum, err = store.Checksum(tpl)
+		tpl.Close()
+		if err == nil && checksum == info.Version {
+			logger.Debugf("Using cached '%s' from remote '%s' (%s)", name, remote, checksum)
+			return nil
+		}
+	}
+
+	logger.Infof("Downloading '%s' from remote '%s'", name, remote)
+
+	var buf = new(bytes.Buffer)
+	if err = client.Download(name, buf); err != nil {
+		return errors.Wrapf(err, "failed to download '%s' from remote '%s'", name, remote)
+	}
+
+	cfg, archive, err := store.ReadExport(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		return errors.Wrapf(err, "failed to read '%s' from remote '%s'", name, remote)
+	}
+
+	return cache.Put(name, cfg, bytes.NewReader(archive))
+}
+
+// ReadRemoteProjectConfig fetches the named template from the remote and reads it from the local cache.
+// The returned function must be called to close the project files.
+func (a *App) ReadRemoteProjectConfig(remote, name string) (proj *config.Project, closeFiles func(), err error) {
+	if err = a.FetchRemoteProject(remote, name); err != nil {
+		return nil, nil, err
+	}
+	return a.readProject(a.RemoteCache(remote), name)
+}
+
+// PublishProject uploads the named template from the local store to the remote.
+// If token is not empty, it is used instead of the token configured for the remote.
+func (a *App) PublishProject(remote, name, token string) (*registry.Template, error) {
+	var client, err = a.RemoteClient(remote)
+	if err != nil {
+		return nil, err
+	}
+
+	if token != "" {
+		client.Token = token
+	}
+
+	tpl, err := a.Store.Get(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get project '%s'", name)
+	}
+	defer tpl.Close()
+
+	var buf = new(bytes.Buffer)
+	if err = store.WriteExport(buf, tpl); err != nil {
+		return nil, errors.Wrapf(err, "failed to write export archive for '%s'", name)
+	}
+
+	logger.Infof("Publishing '%s' to remote '%s'", name, remote)
+
+	published, err := client.Publish(name, buf)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to publish '%s' to remote '%s'", name, remote)
+	}
+
+	return published, nil
+}
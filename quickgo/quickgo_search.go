@@ -0,0 +1,223 @@
+package quickgo
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Nigel2392/goldcrest"
+	"github.com/Nigel2392/quickgo/v2/quickgo/config"
+	"github.com/Nigel2392/quickgo/v2/quickgo/logger"
+	"github.com/Nigel2392/quickgo/v2/quickgo/search"
+	"github.com/Nigel2392/quickgo/v2/quickgo/store"
+	"github.com/pkg/errors"
+)
+
+const (
+	// The default maximum number of search results.
+	defaultSearchLimit = 100
+)
+
+func init() {
+	// Keep the search index up to date when a project is saved.
+	goldcrest.Register(HookProjectAfterSave, 0, func(a *App, proj *config.Project) error {
+		if err := a.IndexProject(proj.Name); err != nil {
+			logger.Warnf("Failed to update search index for project '%s': %v", proj.Name, err)
+		}
+		return nil
+	})
+}
+
+// SearchIndex returns the search index of the saved projects.
+//
+// The index is loaded from the user's config directory the first time it is requested.
+// Templates which were added, changed or removed since the index was last saved are (re)indexed.
+func (a *App) SearchIndex() (*search.Index, error) {
+	a.searchMu.Lock()
+	defer a.searchMu.Unlock()
+
+	if a.searchIndex == nil {
+		a.searchIndex = a.loadSearchIndex()
+	}
+
+	var templates, err = a.Store.List()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list stored projects")
+	}
+
+	var (
+		changed = false
+		stored  = make(map[string]bool, len(templates))
+	)
+
+	for _, tpl := range templates {
+		stored[tpl.Name] = true
+		if modTime, ok := a.searchIndex.ModTime(tpl.Name); ok && modTime.Equal(tpl.ModTime) {
+			continue
+		}
+
+		if err = a.indexProject(tpl.Name); err != nil {
+			return nil, err
+		}
+		changed = true
+	}
+
+	for _, name := range a.searchIndex.Names() {
+		if !stored[name] {
+			a.searchIndex.Remove(name)
+			changed = true
+		}
+	}
+
+	if changed {
+		a.saveSearchIndex()
+	}
+
+	return a.searchIndex, nil
+}
+
+// IndexProject (re)indexes the named project in the search index.
+func (a *App) IndexProject(name string) error {
+	a.searchMu.Lock()
+	defer a.searchMu.Unlock()
+
+	if a.searchIndex == nil {
+		a.searchIndex = a.loadSearchIndex()
+	}
+
+	if err := a.indexProject(name); err != nil {
+		return err
+	}
+
+	a.saveSearchIndex()
+	return nil
+}
+
+// Search searches the file paths and contents of all saved projects.
+// If limit is larger than zero, at most limit results are returned.
+func (a *App) Search(query string, limit int) ([]*search.Result, error) {
+	var ix, err = a.SearchIndex()
+	if err != nil {
+		return nil, err
+	}
+	return ix.Search(query, limit), nil
+}
+
+func (a *App) indexProject(name string) error {
+	var tpl, err = a.Store.Get(name)
+	if errors.Is(err, store.ErrTemplateMissing) {
+		a.searchIndex.Remove(name)
+		return nil
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to get project %s", name)
+	}
+	defer tpl.Close()
+
+	logger.Debugf("Indexing project '%s' for search", name)
+
+	doc, err := search.NewDocument(name, tpl.ModTime, tpl.Archive, tpl.Size)
+	if err != nil {
+		return errors.Wrapf(err, "failed to index project %s", name)
+	}
+
+	a.searchIndex.Put(doc)
+	return nil
+}
+
+func (a *App) loadSearchIndex() *search.Index {
+	if a.SearchIndexPath == "" {
+		return search.NewIndex()
+	}
+
+	var ix, err = search.Load(a.SearchIndexPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warnf("Failed to load search index %s, rebuilding: %v", a.SearchIndexPath, err)
+		}
+		return search.NewIndex()
+	}
+
+	return ix
+}
+
+func (a *App) saveSearchIndex() {
+	if a.SearchIndexPath == "" {
+		return
+	}
+
+	if err := a.searchIndex.Save(a.SearchIndexPath); err != nil {
+		logger.Warnf("Failed to save search index %s: %v", a.SearchIndexPath, err)
+	}
+}
+
+// visibleSearchResults removes the results of templates the principal of the request cannot read.
+func (a *App) visibleSearchResults(r *http.Request, results []*search.Result) ([]*search.Result, error) {
+	var templates, err = a.requestStore(r).List()
+	if err != nil {
+		return nil, err
+	}
+
+	var visible = make(map[string]bool, len(templates))
+	for _, tpl := range templates {
+		visible[tpl.Name] = true
+	}
+
+	var filtered = make([]*search.Result, 0, len(results))
+	for _, result := range results {
+		if visible[result.Template] {
+			filtered = append(filtered, result)
+		}
+	}
+
+	return filtered, nil
+}
+
+// searchRequest runs the search query of the request.
+// The query is taken from the "q" parameter, and the limit from the "limit" parameter.
+func (a *App) searchRequest(r *http.Request) (query string, results []*search.Result, err error) {
+	query = strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		return query, nil, nil
+	}
+
+	var limit = defaultSearchLimit
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = min(l, defaultSearchLimit)
+	}
+
+	// Hidden templates are filtered out after searching, so search without a limit.
+	if results, err = a.Search(query, 0); err != nil {
+		return query, nil, err
+	}
+
+	if results, err = a.visibleSearchResults(r, results); err != nil {
+		return query, nil, err
+	}
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return query, results, nil
+}
+
+// serveSearch serves the search page of the web UI.
+func (a *App) serveSearch(w http.ResponseWriter, r *http.Request) {
+	var query, results, err = a.searchRequest(r)
+	if err != nil {
+		logger.Errorf("Failed to search for '%s': %v", query, err)
+		http.Error(w, "Failed to search", http.StatusInternalServerError)
+		return
+	}
+
+	var ctx = &ProjectTemplateContext{
+		ObjectList: results,
+		Content:    query,
+	}
+
+	if err = a.executeServeTemplate(w, "search.tmpl", ctx); err != nil {
+		logger.Errorf("Failed to render search results: %v", err)
+		http.Error(w, "Failed to render search results", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,112 @@
+package quickgo_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo"
+)
+
+func TestSearch(t *testing.T) {
+	var (
+		app  = newTestApp()
+		proj = newTestProject(t, map[string]string{
+			"Dockerfile":  "FROM golang:1.22\n",
+			"src/main.go": "package main",
+		})
+	)
+
+	if err := app.WriteProjectConfig(proj); err != nil {
+		t.Fatal(err)
+	}
+
+	// The afterSave hook indexes the project.
+	results, err := app.Search("golang", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || results[0].Path != "Dockerfile" {
+		t.Fatalf("expected Dockerfile to match, got %+v", results)
+	}
+
+	// Templates written to the store directly are picked up when searching.
+	var archive = new(bytes.Buffer)
+	var zf = zip.NewWriter(archive)
+	w, err := zf.Create("secret.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("top secret"))
+	if err = zf.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = app.Store.Put("hidden", []byte("name: hidden\nvisibility: private\n"), archive); err != nil {
+		t.Fatal(err)
+	}
+
+	if results, err = app.Search("secret", 0); err != nil || len(results) != 1 {
+		t.Fatalf("expected private template to match, got %+v (%v)", results, err)
+	}
+
+	var server = httptest.NewServer(app.HttpHandler())
+	defer server.Close()
+
+	t.Run("api", func(t *testing.T) {
+		var resp, err = http.Get(server.URL + quickgo.APIPathPrefix + "search?q=package+main")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		var response = new(quickgo.APISearchResponse)
+		if err = json.NewDecoder(resp.Body).Decode(response); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(response.Results) != 1 || response.Results[0].Path != "src/main.go" {
+			t.Fatalf("expected src/main.go to match, got %+v", response.Results)
+		}
+	})
+
+	t.Run("visibility", func(t *testing.T) {
+		var resp, err = http.Get(server.URL + quickgo.APIPathPrefix + "search?q=secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		var response = new(quickgo.APISearchResponse)
+		if err = json.NewDecoder(resp.Body).Decode(response); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(response.Results) != 0 {
+			t.Fatalf("expected private template to be hidden from anonymous users, got %+v", response.Results)
+		}
+	})
+
+	t.Run("html", func(t *testing.T) {
+		var resp, err = http.Get(server.URL + "/search?q=golang")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != http.StatusOK || !strings.Contains(string(data), "<mark>golang</mark>") {
+			t.Fatalf("expected highlighted match, got %d: %s", resp.StatusCode, data)
+		}
+	})
+}
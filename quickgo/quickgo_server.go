@@ -0,0 +1,147 @@
+package quickgo
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/config"
+	"github.com/Nigel2392/quickgo/v2/quickgo/logger"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultReadHeaderTimeout = 10 * time.Second // The maximum duration for reading the headers of a request.
+	DefaultReadTimeout       = 1 * time.Minute  // The default maximum duration for reading a request, including the body.
+	DefaultWriteTimeout      = 2 * time.Minute  // The default maximum duration before timing out writes of a response.
+	DefaultIdleTimeout       = 2 * time.Minute  // The default maximum time to wait for the next request on a keep-alive connection.
+	DefaultShutdownTimeout   = 30 * time.Second // The default maximum time to wait for active requests when shutting down.
+)
+
+// timeouts returns the configured server timeouts, with the defaults filled in.
+func (a *App) timeouts() config.Timeouts {
+	var t = config.Timeouts{
+		Read:     DefaultReadTimeout,
+		Write:    DefaultWriteTimeout,
+		Idle:     DefaultIdleTimeout,
+		Shutdown: DefaultShutdownTimeout,
+	}
+
+	if a.Config == nil || a.Config.Timeouts == nil {
+		return t
+	}
+
+	var c = a.Config.Timeouts
+	if c.Read > 0 {
+		t.Read = c.Read
+	}
+	if c.Write > 0 {
+		t.Write = c.Write
+	}
+	if c.Idle > 0 {
+		t.Idle = c.Idle
+	}
+	if c.Shutdown > 0 {
+		t.Shutdown = c.Shutdown
+	}
+
+	return t
+}
+
+// NewServer returns the HTTP server for the app on the configured host and port.
+func (a *App) NewServer() *http.Server {
+	var t = a.timeouts()
+	return &http.Server{
+		Addr:              fmt.Sprintf("%s:%s", a.Config.Host, a.Config.Port),
+		Handler:           a.HttpHandler(),
+		ReadHeaderTimeout: min(DefaultReadHeaderTimeout, t.Read),
+		ReadTimeout:       t.Read,
+		WriteTimeout:      t.Write,
+		IdleTimeout:       t.Idle,
+	}
+}
+
+// ListenAndServe serves the app on the configured host and port until the context is done.
+// See Serve for more information.
+func (a *App) ListenAndServe(ctx context.Context) error {
+	var server = a.NewServer()
+	var ln, err = net.Listen("tcp", server.Addr)
+	if err != nil {
+		return errors.Wrapf(err, "failed to listen on %s", server.Addr)
+	}
+	return a.Serve(ctx, server, ln)
+}
+
+// Serve serves requests on the listener until the context is done.
+// HTTPS is used if a TLS certificate and key are configured.
+//
+// When the context is done, the app is no longer ready and the server is shut down gracefully:
+// active requests are given the shutdown timeout to finish.
+func (a *App) Serve(ctx context.Context, server *http.Server, ln net.Listener) error {
+	var errs = make(chan error, 1)
+	go func() {
+		if a.Config.TLSKey != "" && a.Config.TLSCert != "" {
+			logger.Infof("Serving on https://%s", ln.Addr())
+			errs <- server.ServeTLS(ln, a.Config.TLSCert, a.Config.TLSKey)
+		} else {
+			logger.Infof("Serving on http://%s", ln.Addr())
+			errs <- server.Serve(ln)
+		}
+	}()
+
+	select {
+	case err := <-errs:
+		return errors.Wrap(err, "failed to serve")
+	case <-ctx.Done():
+	}
+
+	a.shuttingDown.Store(true)
+
+	var timeout = a.timeouts().Shutdown
+	logger.Infof("Shutting down server, waiting up to %s for active requests", timeout)
+
+	var shutdownCtx, cancel = context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return errors.Wrap(err, "failed to shut down server")
+	}
+
+	if err := <-errs; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return errors.Wrap(err, "failed to serve")
+	}
+
+	logger.Info("Server was shut down")
+	return nil
+}
+
+// serveHealth reports that the server is alive.
+func (a *App) serveHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	fmt.Fprintln(w, "ok")
+}
+
+// serveReady reports whether the server is ready to serve requests.
+// It is not ready while shutting down, or when the template store cannot be read.
+func (a *App) serveReady(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+
+	if a.shuttingDown.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintln(w, "shutting down")
+		return
+	}
+
+	if _, err := a.Store.List(); err != nil {
+		logger.Errorf("Readiness check failed to list templates: %v", err)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintln(w, "template store unavailable")
+		return
+	}
+
+	fmt.Fprintln(w, "ok")
+}
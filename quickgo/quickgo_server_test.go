@@ -0,0 +1,122 @@
+package quickgo_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo"
+	"github.com/Nigel2392/quickgo/v2/quickgo/config"
+)
+
+func TestHealthEndpoints(t *testing.T) {
+	var app = newTestApp()
+	app.Config.Auth = &config.Auth{
+		Required: true,
+		Tokens:   []*config.AuthToken{{Name: "test", Token: "secret"}},
+	}
+
+	var err error
+	if app.Auth, err = quickgo.NewAuthenticator(app.Config.Auth); err != nil {
+		t.Fatal(err)
+	}
+
+	var server = httptest.NewServer(app.HttpHandler())
+	defer server.Close()
+
+	for _, path := range []string{"/healthz", "/readyz"} {
+		var resp, err = http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%s: expected status 200 without credentials, got %d", path, resp.StatusCode)
+		}
+	}
+
+	// Other paths still require authentication.
+	if resp, err := http.Get(server.URL + "/"); err != nil {
+		t.Fatal(err)
+	} else if resp.Body.Close(); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status 401, got %d", resp.StatusCode)
+	}
+}
+
+func TestGracefulShutdown(t *testing.T) {
+	var app = newTestApp()
+	app.Config.Timeouts = &config.Timeouts{Shutdown: 5 * time.Second}
+
+	var (
+		started     = make(chan struct{})
+		release     = make(chan struct{})
+		ctx, cancel = context.WithCancel(context.Background())
+		server      = app.NewServer()
+		handler     = server.Handler
+	)
+	defer cancel()
+
+	// A slow request which is still in flight when the server is shut down.
+	var mux = http.NewServeMux()
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	})
+	mux.Handle("/", handler)
+	server.Handler = mux
+
+	var ln, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var served = make(chan error, 1)
+	go func() { served <- app.Serve(ctx, server, ln) }()
+
+	var url = "http://" + ln.Addr().String()
+	var body = make(chan string, 1)
+	go func() {
+		var resp, err = http.Get(url + "/slow")
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		var b, _ = io.ReadAll(resp.Body)
+		body <- string(b)
+	}()
+
+	<-started
+	cancel()
+
+	// Give the server some time to start shutting down, the request must not be cut off.
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case err := <-served:
+		t.Fatalf("server stopped before the active request finished: %v", err)
+	default:
+	}
+
+	close(release)
+
+	if b := <-body; b != "done" {
+		t.Errorf("expected active request to finish, got %q", b)
+	}
+
+	if err := <-served; err != nil {
+		t.Fatalf("expected graceful shutdown, got %v", err)
+	}
+
+	// The app is no longer ready after shutting down.
+	var rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503 after shutdown, got %d", rec.Code)
+	}
+}
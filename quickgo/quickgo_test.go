@@ -0,0 +1,161 @@
+package quickgo_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo"
+	"github.com/Nigel2392/quickgo/v2/quickgo/config"
+	"github.com/Nigel2392/quickgo/v2/quickgo/quickfs"
+	"github.com/Nigel2392/quickgo/v2/quickgo/store"
+)
+
+func newTestProject(t *testing.T, files map[string]string) *config.Project {
+	var dir = t.TempDir()
+	for name, content := range files {
+		var path = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var proj = &config.Project{
+		Name:       "test-project",
+		DelimLeft:  "{{",
+		DelimRight: "}}",
+		Context: map[string]any{
+			"Greeting": "Hello",
+		},
+	}
+
+	// Project files are saved relative to the working directory.
+	var wd, err = os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	if err = proj.Load("."); err != nil {
+		t.Fatal(err)
+	}
+
+	return proj
+}
+
+func newTestApp() *quickgo.App {
+	return &quickgo.App{
+		Config: &config.QuickGo{},
+		Store:  store.NewMemoryStore(),
+		Patterns: []string{
+			"_templates/base.tmpl",
+			"_templates/parent_url.tmpl",
+			"_templates/search_form.tmpl",
+			"_templates/code_lines.tmpl",
+		},
+	}
+}
+
+func TestWriteReadProjectConfig(t *testing.T) {
+	var (
+		app  = newTestApp()
+		proj = newTestProject(t, map[string]string{
+			"README.md":   "{{ .Name }}",
+			"src/main.go": "package main",
+		})
+	)
+
+	if err := app.WriteProjectConfig(proj); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := app.ListProjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(names) != 1 || names[0] != proj.Name {
+		t.Fatalf("expected [%s], got %v", proj.Name, names)
+	}
+
+	read, closeFiles, err := app.ReadProjectConfig(proj.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFiles()
+
+	if read.Context["Greeting"] != "Hello" {
+		t.Errorf("expected context to be read back, got %v", read.Context)
+	}
+
+	fl, err := read.Root.Find([]string{"src", "main.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := io.ReadAll(fl.(*quickfs.FSFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != "package main" {
+		t.Errorf("expected %q, got %q", "package main", data)
+	}
+}
+
+func TestReadProjectConfigMissing(t *testing.T) {
+	var app = newTestApp()
+	if _, _, err := app.ReadProjectConfig("missing"); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestWriteProject(t *testing.T) {
+	var (
+		app  = newTestApp()
+		proj = newTestProject(t, map[string]string{
+			"README.md":           "{{ index .Context \"Greeting\" }} {{ .Name }}",
+			"{{ .Name }}/main.go": "package main",
+		})
+	)
+
+	if err := app.WriteProjectConfig(proj); err != nil {
+		t.Fatal(err)
+	}
+
+	read, closeFiles, err := app.ReadProjectConfig(proj.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFiles()
+
+	var dir = t.TempDir()
+	if err = app.WriteProject(read, dir, false); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, expected := range map[string]string{
+		"README.md":            "Hello test-project",
+		"test-project/main.go": "package main",
+	} {
+		var data, err = os.ReadFile(filepath.Join(dir, proj.Name, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(data) != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, data)
+		}
+	}
+
+	if _, err = os.Stat(filepath.Join(dir, proj.Name, config.PROJECT_CONFIG_NAME)); err != nil {
+		t.Errorf("expected project config to be written: %v", err)
+	}
+}
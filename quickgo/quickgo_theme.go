@@ -0,0 +1,127 @@
+package quickgo
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The directory of the web UI templates and static files in the embedded file system.
+const templatesDir = "_templates"
+
+// themeFS shadows the embedded templates and static files with the files of a theme directory.
+//
+// The theme directory has the same layout as the embedded _templates directory:
+// page templates (base.tmpl, index.tmpl, ...) in its root, and static files in static/.
+type themeFS struct {
+	theme    fs.FS
+	fallback fs.FS
+}
+
+// ThemeFS returns a file system for App.TemplateFS, where the files in dir override the
+// embedded templates and static files of the web UI.
+// Anything which is not overridden falls back to the embedded version.
+func ThemeFS(dir string) (fs.FS, error) {
+	var info, err = os.Stat(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open theme directory %s", dir)
+	}
+
+	if !info.IsDir() {
+		return nil, errors.Errorf("theme %s is not a directory", dir)
+	}
+
+	return &themeFS{
+		theme:    os.DirFS(dir),
+		fallback: embedFS,
+	}, nil
+}
+
+// themePath returns the path of name in the theme directory, false if name is not a template or static file.
+func themePath(name string) (string, bool) {
+	if name == templatesDir {
+		return ".", true
+	}
+	return strings.CutPrefix(name, templatesDir+"/")
+}
+
+// Open opens the file from the theme if it exists as a regular file, otherwise from the embedded files.
+// Directories are always opened from the embedded files, use ReadDir to list the merged directory.
+func (t *themeFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if p, ok := themePath(name); ok {
+		var f, err = t.theme.Open(p)
+		if err == nil {
+			if info, err := f.Stat(); err == nil && info.Mode().IsRegular() {
+				return f, nil
+			}
+			f.Close()
+		}
+	}
+
+	return t.fallback.Open(name)
+}
+
+// ReadDir lists the entries of both the theme and the embedded directory, sorted by name.
+// Entries in the theme take precedence over embedded entries with the same name.
+func (t *themeFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	var entries, err = fs.ReadDir(t.fallback, name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	var p, ok = themePath(name)
+	if !ok {
+		return entries, err
+	}
+
+	themeEntries, themeErr := fs.ReadDir(t.theme, p)
+	if themeErr != nil {
+		return entries, err
+	}
+
+	for _, entry := range themeEntries {
+		var i = slices.IndexFunc(entries, func(e fs.DirEntry) bool {
+			return e.Name() == entry.Name()
+		})
+		if i >= 0 {
+			entries[i] = entry
+		} else {
+			entries = append(entries, entry)
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return entries, nil
+}
+
+// templateFS returns the file system the templates of the web UI are loaded from.
+func (a *App) templateFS() fs.FS {
+	if a.TemplateFS != nil {
+		return a.TemplateFS
+	}
+	return embedFS
+}
+
+// staticFS returns the file system the static files of the web UI are served from.
+func (a *App) staticFS() fs.FS {
+	if a.TemplateFS == nil {
+		return staticFS
+	}
+
+	var sub, err = fs.Sub(a.TemplateFS, path.Join(templatesDir, "static"))
+	if err != nil {
+		return staticFS
+	}
+	return sub
+}
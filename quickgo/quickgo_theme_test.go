@@ -0,0 +1,137 @@
+package quickgo_test
+
+import (
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo"
+)
+
+func TestTheme(t *testing.T) {
+	var dir = t.TempDir()
+	var files = map[string]string{
+		"base.tmpl":           `{{ define "base" }}<html><body><h1>ACME Templates</h1>{{ block "content" . }}{{ end }}</body></html>{{ end }}`,
+		"static/template.css": "body { color: red; }",
+	}
+
+	for name, content := range files {
+		var p = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var (
+		app  = newTestApp()
+		proj = newTestProject(t, map[string]string{
+			"README.txt": "Hello",
+		})
+		err error
+	)
+
+	if app.TemplateFS, err = quickgo.ThemeFS(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = app.WriteProjectConfig(proj); err != nil {
+		t.Fatal(err)
+	}
+
+	var server = httptest.NewServer(app.HttpHandler())
+	defer server.Close()
+
+	var get = func(t *testing.T, path string) (int, string) {
+		t.Helper()
+		var resp, err = http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var body, _ = io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	t.Run("override", func(t *testing.T) {
+		var code, body = get(t, "/")
+		if code != http.StatusOK || !strings.Contains(body, "<h1>ACME Templates</h1>") {
+			t.Fatalf("expected the overridden base template, got %d: %s", code, body)
+		}
+
+		// The index template itself is not overridden.
+		if !strings.Contains(body, "test-project") {
+			t.Errorf("expected the embedded index template, got: %s", body)
+		}
+
+		if code, body = get(t, "/static/template.css"); code != http.StatusOK || body != files["static/template.css"] {
+			t.Errorf("expected the overridden stylesheet, got %d: %s", code, body)
+		}
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		if code, _ := get(t, "/static/quickgo.png"); code != http.StatusOK {
+			t.Errorf("expected the embedded static file, got %d", code)
+		}
+		if code, _ := get(t, "/favicon.ico"); code != http.StatusOK {
+			t.Errorf("expected the embedded favicon, got %d", code)
+		}
+
+		var entries, err = fs.ReadDir(app.TemplateFS, "_templates/static")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var names = make([]string, len(entries))
+		for i, entry := range entries {
+			names[i] = entry.Name()
+		}
+		if !slices.Equal(names, []string{"quickgo.png", "template.css"}) {
+			t.Errorf("expected the merged static directory, got %v", names)
+		}
+	})
+
+	t.Run("etag", func(t *testing.T) {
+		// The same project served without the theme, like before the theme was configured.
+		var plain = newTestApp()
+		plain.Store = app.Store
+
+		var plainServer = httptest.NewServer(plain.HttpHandler())
+		defer plainServer.Close()
+
+		var resp, err = http.Get(plainServer.URL + "/projects/test-project/README.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		var req, _ = http.NewRequest(http.MethodGet, server.URL+"/projects/test-project/README.txt", nil)
+		req.Header.Set("If-None-Match", resp.Header.Get("ETag"))
+		if resp, err = http.DefaultClient.Do(req); err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		var body, _ = io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "<h1>ACME Templates</h1>") {
+			t.Errorf("expected the page with the theme, got %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := quickgo.ThemeFS(filepath.Join(dir, "base.tmpl")); err == nil {
+			t.Error("expected an error for a theme which is not a directory")
+		}
+		if _, err := quickgo.ThemeFS(filepath.Join(dir, "missing")); err == nil {
+			t.Error("expected an error for a missing theme directory")
+		}
+	})
+}
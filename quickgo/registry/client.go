@@ -0,0 +1,131 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client talks to a remote quickgo server.
+type Client struct {
+	// The base URL of the remote server, e.g. http://host:8080
+	URL string
+
+	// The bearer token to authenticate with, only required for publishing.
+	Token string
+
+	// The HTTP client to use, defaults to a client with a sane timeout.
+	HTTPClient *http.Client
+}
+
+// NewClient returns a client for the server at the given base URL.
+func NewClient(baseURL string) *Client {
+	return &Client{
+		URL: strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+// List returns all templates available on the remote server.
+func (c *Client) List() ([]*Template, error) {
+	var list = new(TemplateList)
+	if err := c.getJSON(TemplatesPath(), list); err != nil {
+		return nil, err
+	}
+	return list.Templates, nil
+}
+
+// Template returns the metadata and versions of a single template.
+func (c *Client) Template(name string) (*Template, error) {
+	var tpl = new(Template)
+	if err := c.getJSON(TemplatePath(url.PathEscape(name)), tpl); err != nil {
+		return nil, err
+	}
+	return tpl, nil
+}
+
+// Download writes the export archive of the named template to w.
+func (c *Client) Download(name string, w io.Writer) error {
+	var resp, err = c.do(http.MethodGet, ArchivePath(url.PathEscape(name)), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// Publish uploads the export archive of the named template to the remote server.
+func (c *Client) Publish(name string, archive io.Reader) (*Template, error) {
+	var resp, err = c.do(http.MethodPut, TemplatePath(url.PathEscape(name)), archive)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var tpl = new(Template)
+	if err = json.NewDecoder(resp.Body).Decode(tpl); err != nil {
+		return nil, fmt.Errorf("failed to decode publish response: %w", err)
+	}
+
+	return tpl, nil
+}
+
+func (c *Client) getJSON(path string, v any) error {
+	var resp, err = c.do(http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err = json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response from %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *Client) do(method, path string, body io.Reader) (*http.Response, error) {
+	var req, err = http.NewRequest(method, c.URL+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", ArchiveContentType)
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	var httpClient = c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+
+	var errResp = &ErrorResponse{Status: resp.StatusCode}
+	if err = json.NewDecoder(resp.Body).Decode(errResp); err != nil || errResp.Message == "" {
+		errResp.Message = http.StatusText(resp.StatusCode)
+	}
+
+	return nil, errResp
+}
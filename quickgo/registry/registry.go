@@ -0,0 +1,72 @@
+package registry
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// The path prefix for all registry endpoints.
+	PathPrefix = "/registry/v1/"
+
+	// The content type of a template's export archive.
+	ArchiveContentType = "application/zip"
+
+	// The maximum size of an export archive which can be published.
+	MaxPublishSize = 128 << 20
+)
+
+type (
+	// Version is a single published version of a template.
+	Version struct {
+		// The sha256 checksum of the template's configuration and archive.
+		Version string    `json:"version" yaml:"version"`
+		Size    int64     `json:"size" yaml:"size"`
+		ModTime time.Time `json:"modTime" yaml:"modTime"`
+	}
+
+	// Template is the registry's view of a stored template.
+	Template struct {
+		Name    string    `json:"name" yaml:"name"`
+		Size    int64     `json:"size" yaml:"size"`
+		ModTime time.Time `json:"modTime" yaml:"modTime"`
+
+		// The current version of the template.
+		// Only included when requesting a single template.
+		Version string `json:"version,omitempty" yaml:"version,omitempty"`
+
+		// All versions of the template which are available for download.
+		// Only included when requesting a single template.
+		Versions []Version `json:"versions,omitempty" yaml:"versions,omitempty"`
+	}
+
+	// TemplateList is the response body when listing templates.
+	TemplateList struct {
+		Templates []*Template `json:"templates"`
+	}
+
+	// ErrorResponse is the response body of any failed request.
+	ErrorResponse struct {
+		Status  int    `json:"status"`
+		Message string `json:"error"`
+	}
+)
+
+func (e *ErrorResponse) Error() string {
+	return fmt.Sprintf("registry returned status %d: %s", e.Status, e.Message)
+}
+
+// TemplatesPath returns the path for the list of templates.
+func TemplatesPath() string {
+	return PathPrefix + "templates"
+}
+
+// TemplatePath returns the path for a single template.
+func TemplatePath(name string) string {
+	return TemplatesPath() + "/" + name
+}
+
+// ArchivePath returns the path for the export archive of a template.
+func ArchivePath(name string) string {
+	return TemplatePath(name) + "/archive"
+}
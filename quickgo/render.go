@@ -2,7 +2,7 @@ package quickgo
 
 import (
 	"fmt"
-	"runtime/debug"
+	"os"
 	"strings"
 
 	"github.com/Nigel2392/quickgo/v2/quickgo/logger"
@@ -21,7 +21,25 @@ const (
 	CMD_Reset         = "\033[0m"
 )
 
+// NoColor disables the colors added by Craft and BuildColorString.
+// It is set when the NO_COLOR environment variable is set, or when stdout is not a terminal.
+var NoColor = os.Getenv("NO_COLOR") != "" || !IsTerminal(os.Stdout)
+
+// IsTerminal reports whether the file is a terminal (character device).
+func IsTerminal(f *os.File) bool {
+	var stat, err = f.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+// StripColors removes all ANSI escape sequences from s.
+func StripColors(s string) string {
+	return re.ReplaceAllString(s, "")
+}
+
 func Craft(color, s any) string {
+	if NoColor {
+		return fmt.Sprint(s)
+	}
 	return fmt.Sprintf("%s%v%s", color, s, CMD_Reset)
 }
 
@@ -30,6 +48,9 @@ func BuildColorString(colors ...string) string {
 	for _, color := range colors {
 		s.WriteString(color)
 	}
+	if NoColor {
+		return StripColors(s.String())
+	}
 	s.WriteString(CMD_Reset)
 	return s.String()
 }
@@ -45,11 +66,14 @@ func PrintLogo() {
 		Craft(CMD_Purple, "\\$$$$$$ / \\$$$$$$  |$$ |\\$$$$$$$\\ $$ | \\$$\\   "+Craft(CMD_Cyan, " \\$$$$$$  |\\$$$$$$  | #####\n")) +
 		Craft(CMD_Red, " \\___"+CMD_Reset+Craft(CMD_Purple, "$$$")+Craft(CMD_Red, "\\  \\______/ \\__| \\_______|\\__|  \\__| ")+Craft(CMD_Cyan, "   \\______/  \\______/\n")) +
 		Craft(CMD_Red, "     \\___|")
+	if NoColor {
+		str = StripColors(str)
+	}
 	fmt.Println(str)
 	fmt.Println(Craft(CMD_Red, "\nCreated by: ") + Craft(CMD_Purple, "Nigel van Keulen"))
 
-	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
-		fmt.Printf(Craft(CMD_Cyan, "Version: %s\n"), info.Main.Version)
+	if version := Version(); version != "" {
+		fmt.Printf(Craft(CMD_Cyan, "Version: %s\n"), version)
 	}
 
 	// Example:
@@ -0,0 +1,407 @@
+package search
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/gob"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// Files larger than this are only indexed by their path.
+	MaxFileSize = 1 << 20
+
+	// The maximum number of matching lines reported per file.
+	MaxLinesPerFile = 10
+
+	// The maximum length of a reported line, longer lines are cut off.
+	MaxLineLength = 200
+)
+
+type (
+	// Index is a full-text index of the file paths and text contents of templates.
+	// It is safe for concurrent use.
+	Index struct {
+		mu        sync.RWMutex
+		documents map[string]*Document
+	}
+
+	// Document holds the indexed files of a single template.
+	Document struct {
+		// The name of the template.
+		Name string
+
+		// The modification time of the template when it was indexed.
+		ModTime time.Time
+
+		// The indexed files, in archive order.
+		Files []*File
+	}
+
+	// File is a single indexed file.
+	File struct {
+		// The slash separated path of the file in the template.
+		Path string
+
+		// The text content of the file.
+		// Empty for binary and large files.
+		Content string
+	}
+
+	// Result is a file which matched a search query.
+	Result struct {
+		// The name of the template.
+		Template string `json:"template" yaml:"template"`
+
+		// The path of the file in the template.
+		Path string `json:"path" yaml:"path"`
+
+		// The ranges in the path which matched the query.
+		PathMatches []Range `json:"pathMatches,omitempty" yaml:"pathMatches,omitempty"`
+
+		// The lines in the file which matched the query.
+		Lines []*Line `json:"lines,omitempty" yaml:"lines,omitempty"`
+	}
+
+	// Line is a line in a file which matched a search query.
+	Line struct {
+		// The line number, starting at 1.
+		Number int `json:"number" yaml:"number"`
+
+		// The text of the line.
+		Text string `json:"text" yaml:"text"`
+
+		// The ranges in the text which matched the query.
+		Matches []Range `json:"matches" yaml:"matches"`
+	}
+
+	// Range is a byte range [Start, End) of a match.
+	Range struct {
+		Start int `json:"start" yaml:"start"`
+		End   int `json:"end" yaml:"end"`
+	}
+
+	// Fragment is a part of a highlighted string.
+	Fragment struct {
+		Text  string
+		Match bool
+	}
+)
+
+// NewIndex returns an empty index.
+func NewIndex() *Index {
+	return &Index{
+		documents: make(map[string]*Document),
+	}
+}
+
+// Load reads an index which was written with Save.
+func Load(path string) (*Index, error) {
+	var f, err = os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var documents = make(map[string]*Document)
+	if err = gob.NewDecoder(f).Decode(&documents); err != nil {
+		return nil, err
+	}
+
+	return &Index{
+		documents: documents,
+	}, nil
+}
+
+// Save writes the index to path.
+func (ix *Index) Save(path string) error {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	var buf = new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(ix.documents); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, to not leave a corrupt index behind.
+	var tmp = path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// NewDocument indexes the files in the zipped archive of a template.
+func NewDocument(name string, modTime time.Time, archive io.ReaderAt, size int64) (*Document, error) {
+	var zr, err = zip.NewReader(archive, size)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc = &Document{
+		Name:    name,
+		ModTime: modTime,
+		Files:   make([]*File, 0, len(zr.File)),
+	}
+
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || f.Name == "." || f.Name == "./" {
+			continue
+		}
+
+		var file = &File{
+			Path: filepath.ToSlash(f.Name),
+		}
+
+		if f.UncompressedSize64 <= MaxFileSize {
+			var r, err = f.Open()
+			if err != nil {
+				return nil, err
+			}
+
+			content, err := io.ReadAll(r)
+			r.Close()
+			if err != nil {
+				return nil, err
+			}
+
+			if utf8.Valid(content) {
+				file.Content = string(content)
+			}
+		}
+
+		doc.Files = append(doc.Files, file)
+	}
+
+	return doc, nil
+}
+
+// Put adds or replaces the document of a template.
+func (ix *Index) Put(doc *Document) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.documents[doc.Name] = doc
+}
+
+// Remove removes the document of a template.
+func (ix *Index) Remove(name string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	delete(ix.documents, name)
+}
+
+// ModTime returns the modification time of the indexed template,
+// and false if the template is not in the index.
+func (ix *Index) ModTime(name string) (time.Time, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	var doc, ok = ix.documents[name]
+	if !ok {
+		return time.Time{}, false
+	}
+	return doc.ModTime, true
+}
+
+// Names returns the names of all indexed templates in sorted order.
+func (ix *Index) Names() []string {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	var names = make([]string, 0, len(ix.documents))
+	for name := range ix.documents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Search returns the files which contain all words in the query, either in their path or content.
+// Matching is case-insensitive, results are sorted by template name and then file path.
+//
+// If limit is larger than zero, at most limit results are returned.
+func (ix *Index) Search(query string, limit int) []*Result {
+	var terms = strings.Fields(lower(query))
+	if len(terms) == 0 {
+		return nil
+	}
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	var names = make([]string, 0, len(ix.documents))
+	for name := range ix.documents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var results = make([]*Result, 0)
+	for _, name := range names {
+		var files = append([]*File(nil), ix.documents[name].Files...)
+		sort.SliceStable(files, func(i, j int) bool {
+			return files[i].Path < files[j].Path
+		})
+
+		for _, file := range files {
+			var result = searchFile(name, file, terms)
+			if result == nil {
+				continue
+			}
+
+			results = append(results, result)
+			if limit > 0 && len(results) >= limit {
+				return results
+			}
+		}
+	}
+
+	return results
+}
+
+func searchFile(template string, file *File, terms []string) *Result {
+	var (
+		lowerPath    = lower(file.Path)
+		lowerContent = lower(file.Content)
+	)
+
+	for _, term := range terms {
+		if !strings.Contains(lowerPath, term) && !strings.Contains(lowerContent, term) {
+			return nil
+		}
+	}
+
+	var result = &Result{
+		Template:    template,
+		Path:        file.Path,
+		PathMatches: findRanges(lowerPath, terms),
+	}
+
+	if file.Content == "" {
+		return result
+	}
+
+	var lines = strings.Split(file.Content, "\n")
+	var lowerLines = strings.Split(lowerContent, "\n")
+	for i, l := range lowerLines {
+		var matches = findRanges(l, terms)
+		if len(matches) == 0 {
+			continue
+		}
+
+		var text = strings.TrimRight(lines[i], "\r")
+		if len(text) > MaxLineLength {
+			// Cut off at a valid utf-8 boundary.
+			var end = MaxLineLength
+			for end > 0 && !utf8.RuneStart(text[end]) {
+				end--
+			}
+			text = text[:end]
+
+			var kept = matches[:0]
+			for _, m := range matches {
+				if m.Start < end {
+					m.End = min(m.End, end)
+					kept = append(kept, m)
+				}
+			}
+			matches = kept
+		}
+
+		result.Lines = append(result.Lines, &Line{
+			Number:  i + 1,
+			Text:    text,
+			Matches: matches,
+		})
+
+		if len(result.Lines) >= MaxLinesPerFile {
+			break
+		}
+	}
+
+	return result
+}
+
+// findRanges returns the sorted, non overlapping ranges of all occurrences of the terms in s.
+func findRanges(s string, terms []string) []Range {
+	var ranges = make([]Range, 0)
+	for _, term := range terms {
+		for offset := 0; offset < len(s); {
+			var i = strings.Index(s[offset:], term)
+			if i < 0 {
+				break
+			}
+			ranges = append(ranges, Range{Start: offset + i, End: offset + i + len(term)})
+			offset += i + len(term)
+		}
+	}
+
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].Start < ranges[j].Start
+	})
+
+	var merged = ranges[:1]
+	for _, r := range ranges[1:] {
+		var last = &merged[len(merged)-1]
+		if r.Start <= last.End {
+			last.End = max(last.End, r.End)
+			continue
+		}
+		merged = append(merged, r)
+	}
+
+	return merged
+}
+
+// Highlight splits s into fragments, marking the fragments which are in one of the ranges.
+func Highlight(s string, ranges []Range) []Fragment {
+	var (
+		fragments = make([]Fragment, 0, len(ranges)*2+1)
+		offset    = 0
+	)
+
+	for _, r := range ranges {
+		if r.Start > offset {
+			fragments = append(fragments, Fragment{Text: s[offset:r.Start]})
+		}
+		fragments = append(fragments, Fragment{Text: s[r.Start:r.End], Match: true})
+		offset = r.End
+	}
+
+	if offset < len(s) {
+		fragments = append(fragments, Fragment{Text: s[offset:]})
+	}
+
+	return fragments
+}
+
+// lower lowercases the ASCII letters in s.
+// Other characters are left untouched, so byte offsets stay valid for the original string.
+func lower(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		var c = s[i]
+		if c >= 'A' && c <= 'Z' {
+			if b == nil {
+				b = []byte(s)
+			}
+			b[i] = c + ('a' - 'A')
+		}
+	}
+	if b == nil {
+		return s
+	}
+	return string(b)
+}
@@ -0,0 +1,122 @@
+package search_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/search"
+)
+
+func newDocument(t *testing.T, name string, files map[string]string) *search.Document {
+	t.Helper()
+	var buf = new(bytes.Buffer)
+	var zf = zip.NewWriter(buf)
+	for path, content := range files {
+		var w, err = zf.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zf.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc, err = search.NewDocument(name, time.Now(), bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestSearch(t *testing.T) {
+	var ix = search.NewIndex()
+	ix.Put(newDocument(t, "go-api", map[string]string{
+		"Dockerfile": "FROM golang:1.22\nRUN go build ./...\n",
+		"main.go":    "package main\n\nfunc main() {}\n",
+		"logo.png":   "\x89PNG\xff\xfe",
+	}))
+	ix.Put(newDocument(t, "web", map[string]string{
+		"docker/Dockerfile": "FROM node:20\n",
+	}))
+
+	var results = ix.Search("dockerfile", 0)
+	if len(results) != 2 || results[0].Template != "go-api" || results[1].Path != "docker/Dockerfile" {
+		t.Fatalf("expected both Dockerfiles, got %+v", results)
+	}
+
+	if len(results[1].PathMatches) != 1 || results[1].PathMatches[0] != (search.Range{Start: 7, End: 17}) {
+		t.Errorf("expected path match on the file name, got %v", results[1].PathMatches)
+	}
+
+	results = ix.Search("FROM golang", 0)
+	if len(results) != 1 || results[0].Template != "go-api" {
+		t.Fatalf("expected 1 result, got %+v", results)
+	}
+
+	var line = results[0].Lines[0]
+	if line.Number != 1 || len(line.Matches) != 2 || line.Matches[1] != (search.Range{Start: 5, End: 11}) {
+		t.Errorf("expected matches on line 1, got %+v", line)
+	}
+
+	if results = ix.Search("png", 0); len(results) != 1 || len(results[0].Lines) != 0 {
+		t.Errorf("expected binary file to only match on path, got %+v", results)
+	}
+
+	if results = ix.Search("FROM", 1); len(results) != 1 {
+		t.Errorf("expected results to be limited to 1, got %d", len(results))
+	}
+
+	if results = ix.Search("  ", 0); results != nil {
+		t.Errorf("expected no results for empty query, got %+v", results)
+	}
+
+	ix.Remove("web")
+	if results = ix.Search("node", 0); len(results) != 0 {
+		t.Errorf("expected removed template not to be searched, got %+v", results)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	var ix = search.NewIndex()
+	ix.Put(newDocument(t, "go-api", map[string]string{
+		"main.go": "package main",
+	}))
+
+	var path = filepath.Join(t.TempDir(), "search.idx")
+	if err := ix.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := search.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if results := loaded.Search("package", 0); len(results) != 1 {
+		t.Fatalf("expected loaded index to be searchable, got %+v", results)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	var fragments = search.Highlight("hello world", []search.Range{{Start: 0, End: 5}, {Start: 6, End: 8}})
+	var expected = []search.Fragment{
+		{Text: "hello", Match: true},
+		{Text: " "},
+		{Text: "wo", Match: true},
+		{Text: "rld"},
+	}
+
+	if len(fragments) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, fragments)
+	}
+
+	for i := range expected {
+		if fragments[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], fragments[i])
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package store
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/config"
+)
+
+// DiskStore keeps templates on the local disk.
+//
+// Every template is a directory inside of the store's root,
+// containing the project configuration and the project's zip archive.
+type DiskStore struct {
+	Dir string
+}
+
+// NewDiskStore returns a store rooted at the given directory.
+func NewDiskStore(dir string) *DiskStore {
+	return &DiskStore{Dir: dir}
+}
+
+func (s *DiskStore) path(name string, file ...string) string {
+	return filepath.Join(append([]string{s.Dir, name}, file...)...)
+}
+
+func (s *DiskStore) List() ([]*Template, error) {
+	var dir, err = os.ReadDir(s.Dir)
+	if err != nil && os.IsNotExist(err) {
+		return []*Template{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var templates = make([]*Template, 0, len(dir))
+	for _, d := range dir {
+		if !d.IsDir() {
+			continue
+		}
+
+		var meta, err = s.Stat(d.Name())
+		if err != nil && errors.Is(err, ErrTemplateMissing) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		cfg, err := os.ReadFile(s.path(d.Name(), config.PROJECT_CONFIG_NAME))
+		if err != nil {
+			return nil, err
+		}
+
+		templates = append(templates, &Template{
+			Metadata: *meta,
+			Config:   cfg,
+		})
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+
+	return templates, nil
+}
+
+func (s *DiskStore) Stat(name string) (*Metadata, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	var cfgStat, err = os.Stat(s.path(name, config.PROJECT_CONFIG_NAME))
+	if err != nil && os.IsNotExist(err) {
+		return nil, ErrTemplateMissing
+	} else if err != nil {
+		return nil, err
+	}
+
+	var meta = &Metadata{
+		Name:    name,
+		ModTime: cfgStat.ModTime(),
+	}
+
+	zipStat, err := os.Stat(s.path(name, config.PROJECT_ZIP_NAME))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	} else if err == nil {
+		meta.Size = zipStat.Size()
+		if zipStat.ModTime().After(meta.ModTime) {
+			meta.ModTime = zipStat.ModTime()
+		}
+	}
+
+	return meta, nil
+}
+
+func (s *DiskStore) Get(name string) (*Template, error) {
+	var meta, err = s.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := os.ReadFile(s.path(name, config.PROJECT_CONFIG_NAME))
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(s.path(name, config.PROJECT_ZIP_NAME))
+	if err != nil && os.IsNotExist(err) {
+		return nil, ErrTemplateMissing
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &Template{
+		Metadata: *meta,
+		Config:   cfg,
+		Archive:  file,
+		closer:   file,
+	}, nil
+}
+
+func (s *DiskStore) Put(name string, cfg []byte, archive io.Reader) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+
+	var dirPath = s.path(name)
+	if err := os.MkdirAll(dirPath, os.ModePerm); err != nil {
+		return err
+	}
+
+	// Write the archive to a temporary file first.
+	// This way a failed write never leaves a half-written template behind.
+	var tmp, err = os.CreateTemp(dirPath, config.PROJECT_ZIP_NAME+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, archive); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Rename(tmp.Name(), s.path(name, config.PROJECT_ZIP_NAME)); err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path(name, config.PROJECT_CONFIG_NAME), cfg, os.ModePerm)
+}
+
+func (s *DiskStore) Delete(name string) error {
+	if _, err := s.Stat(name); err != nil {
+		return err
+	}
+	return os.RemoveAll(s.path(name))
+}
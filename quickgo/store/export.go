@@ -0,0 +1,113 @@
+package store
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/config"
+)
+
+var (
+	ErrExportInvalid  = errors.New("invalid template export archive")
+	ErrExportTooLarge = errors.New("template export archive is too large")
+)
+
+// MaxExportSize is the maximum uncompressed size of the files in an export archive,
+// and of the files in the project archive inside of it.
+// It protects readers against archives which decompress to far more data than they take up.
+var MaxExportSize int64 = 512 << 20
+
+// WriteExport writes the template as an export archive to w.
+//
+// The export archive is a zip file containing the project configuration
+// and the project's own zip archive.
+// The template must have been retrieved with Get.
+func WriteExport(w io.Writer, t *Template) error {
+	var zf = zip.NewWriter(w)
+
+	if err := copyToZip(zf, config.PROJECT_CONFIG_NAME, bytes.NewReader(t.Config)); err != nil {
+		return err
+	}
+
+	if err := copyToZip(zf, config.PROJECT_ZIP_NAME, io.NewSectionReader(t.Archive, 0, t.Size)); err != nil {
+		return err
+	}
+
+	return zf.Close()
+}
+
+// ReadExport reads an export archive written by WriteExport.
+// It returns the raw project configuration and the project's zip archive.
+func ReadExport(r io.ReaderAt, size int64) (cfg []byte, archive []byte, err error) {
+	zf, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrExportInvalid, err)
+	}
+
+	for _, f := range zf.File {
+		var dst *[]byte
+		switch f.Name {
+		case config.PROJECT_CONFIG_NAME:
+			dst = &cfg
+		case config.PROJECT_ZIP_NAME:
+			dst = &archive
+		default:
+			continue
+		}
+
+		if f.UncompressedSize64 > uint64(MaxExportSize) {
+			return nil, nil, fmt.Errorf("%w: %s is larger than %d bytes", ErrExportTooLarge, f.Name, MaxExportSize)
+		}
+
+		var rc, err = f.Open()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// The size in the header cannot be trusted, read at most one byte more than the limit.
+		*dst, err = io.ReadAll(io.LimitReader(rc, MaxExportSize+1))
+		rc.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if int64(len(*dst)) > MaxExportSize {
+			return nil, nil, fmt.Errorf("%w: %s is larger than %d bytes", ErrExportTooLarge, f.Name, MaxExportSize)
+		}
+	}
+
+	if cfg == nil || archive == nil {
+		return nil, nil, fmt.Errorf(
+			"%w: missing %s or %s",
+			ErrExportInvalid, config.PROJECT_CONFIG_NAME, config.PROJECT_ZIP_NAME,
+		)
+	}
+
+	return cfg, archive, nil
+}
+
+// Checksum returns the hex encoded sha256 checksum of the template's configuration and archive.
+// The template must have been retrieved with Get.
+func Checksum(t *Template) (string, error) {
+	var h = sha256.New()
+	h.Write(t.Config)
+	if _, err := io.Copy(h, io.NewSectionReader(t.Archive, 0, t.Size)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func copyToZip(z *zip.Writer, name string, r io.Reader) error {
+	var w, err = z.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to create '%s': %w", name, err)
+	}
+
+	_, err = io.Copy(w, r)
+	return err
+}
@@ -0,0 +1,130 @@
+package store
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+type memoryTemplate struct {
+	meta    Metadata
+	config  []byte
+	archive []byte
+}
+
+// MemoryStore keeps templates in memory.
+// It is safe for concurrent use.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	templates map[string]*memoryTemplate
+}
+
+// NewMemoryStore returns an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		templates: make(map[string]*memoryTemplate),
+	}
+}
+
+func (s *MemoryStore) List() ([]*Template, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var templates = make([]*Template, 0, len(s.templates))
+	for _, t := range s.templates {
+		templates = append(templates, &Template{
+			Metadata: t.meta,
+			Config:   slices.Clone(t.config),
+		})
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+
+	return templates, nil
+}
+
+func (s *MemoryStore) Stat(name string) (*Metadata, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var t, ok = s.templates[name]
+	if !ok {
+		return nil, ErrTemplateMissing
+	}
+
+	var meta = t.meta
+	return &meta, nil
+}
+
+func (s *MemoryStore) Get(name string) (*Template, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var t, ok = s.templates[name]
+	if !ok {
+		return nil, ErrTemplateMissing
+	}
+
+	// The stored byte slices are never modified, only replaced.
+	// Handing out a reader over them is safe.
+	return &Template{
+		Metadata: t.meta,
+		Config:   slices.Clone(t.config),
+		Archive:  bytes.NewReader(t.archive),
+	}, nil
+}
+
+func (s *MemoryStore) Put(name string, config []byte, archive io.Reader) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+
+	var data, err = io.ReadAll(archive)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.templates[name] = &memoryTemplate{
+		meta: Metadata{
+			Name:    name,
+			Size:    int64(len(data)),
+			ModTime: time.Now(),
+		},
+		config:  slices.Clone(config),
+		archive: data,
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) Delete(name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.templates[name]; !ok {
+		return ErrTemplateMissing
+	}
+
+	delete(s.templates, name)
+	return nil
+}
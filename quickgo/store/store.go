@@ -0,0 +1,129 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	BackendDisk   = "disk"   // Stores templates in the projects directory, this is the default.
+	BackendMemory = "memory" // Stores templates in memory, mostly useful for testing.
+)
+
+var (
+	ErrTemplateMissing = errors.New("template not found")
+	ErrTemplateName    = errors.New("template name cannot be a blank string, period or filepath")
+	ErrBackendMissing  = errors.New("template store backend not found")
+)
+
+type (
+	// Metadata describes a template which is kept in a TemplateStore.
+	Metadata struct {
+		// The name of the template.
+		Name string `json:"name"`
+
+		// The size of the template's archive in bytes.
+		Size int64 `json:"size"`
+
+		// The last time the template was written to the store.
+		ModTime time.Time `json:"modTime"`
+	}
+
+	// Template is a template retrieved from a TemplateStore.
+	Template struct {
+		Metadata
+
+		// The raw (YAML) project configuration.
+		Config []byte
+
+		// The zipped project files.
+		// This is only set when the template is retrieved with Get,
+		// the template must then always be closed after use.
+		Archive io.ReaderAt
+
+		closer io.Closer
+	}
+
+	// TemplateStore is the storage backend for saved project templates.
+	//
+	// A template consists of the project configuration and a zip archive
+	// holding the project files.
+	TemplateStore interface {
+		// List returns all templates in the store.
+		// The archives of the returned templates are not opened.
+		List() ([]*Template, error)
+
+		// Stat returns the metadata for the named template.
+		Stat(name string) (*Metadata, error)
+
+		// Get returns the named template with its archive opened.
+		Get(name string) (*Template, error)
+
+		// Put creates or overwrites the named template.
+		Put(name string, config []byte, archive io.Reader) error
+
+		// Delete removes the named template from the store.
+		Delete(name string) error
+	}
+
+	// OpenFunc opens a TemplateStore rooted at the given directory.
+	OpenFunc func(dir string) (TemplateStore, error)
+)
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]OpenFunc{
+		BackendDisk: func(dir string) (TemplateStore, error) {
+			return NewDiskStore(dir), nil
+		},
+		BackendMemory: func(dir string) (TemplateStore, error) {
+			return NewMemoryStore(), nil
+		},
+	}
+)
+
+// Register makes a store backend available by the provided name.
+// The name can then be used in the global configuration to select the backend.
+func Register(backend string, fn OpenFunc) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	backends[backend] = fn
+}
+
+// Open opens the store for the named backend.
+// An empty backend name will open the default disk store.
+func Open(backend string, dir string) (TemplateStore, error) {
+	if backend == "" {
+		backend = BackendDisk
+	}
+
+	backendsMu.RLock()
+	var fn, ok = backends[backend]
+	backendsMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrBackendMissing, backend)
+	}
+
+	return fn(dir)
+}
+
+// Close closes the template's archive, if it was opened.
+func (t *Template) Close() error {
+	if t.closer != nil {
+		return t.closer.Close()
+	}
+	return nil
+}
+
+// ValidateName checks if the name can safely be used to address a template.
+func ValidateName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return ErrTemplateName
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package store_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/store"
+)
+
+func testStore(t *testing.T, s store.TemplateStore) {
+	var (
+		config  = []byte("name: my-project\n")
+		archive = []byte("not really a zip file")
+	)
+
+	if err := s.Put("my-project", config, bytes.NewReader(archive)); err != nil {
+		t.Fatalf("failed to put template: %v", err)
+	}
+
+	meta, err := s.Stat("my-project")
+	if err != nil {
+		t.Fatalf("failed to stat template: %v", err)
+	}
+
+	if meta.Name != "my-project" {
+		t.Errorf("expected name %q, got %q", "my-project", meta.Name)
+	}
+
+	if meta.Size != int64(len(archive)) {
+		t.Errorf("expected size %d, got %d", len(archive), meta.Size)
+	}
+
+	tpl, err := s.Get("my-project")
+	if err != nil {
+		t.Fatalf("failed to get template: %v", err)
+	}
+
+	if !bytes.Equal(tpl.Config, config) {
+		t.Errorf("expected config %q, got %q", config, tpl.Config)
+	}
+
+	data, err := io.ReadAll(io.NewSectionReader(tpl.Archive, 0, tpl.Size))
+	if err != nil {
+		t.Fatalf("failed to read archive: %v", err)
+	}
+
+	if !bytes.Equal(data, archive) {
+		t.Errorf("expected archive %q, got %q", archive, data)
+	}
+
+	if err = tpl.Close(); err != nil {
+		t.Fatalf("failed to close template: %v", err)
+	}
+
+	list, err := s.List()
+	if err != nil {
+		t.Fatalf("failed to list templates: %v", err)
+	}
+
+	if len(list) != 1 || list[0].Name != "my-project" {
+		t.Fatalf("expected 1 template named %q, got %v", "my-project", list)
+	}
+
+	if list[0].Archive != nil {
+		t.Errorf("expected listed template archive to be nil")
+	}
+
+	if err = s.Delete("my-project"); err != nil {
+		t.Fatalf("failed to delete template: %v", err)
+	}
+
+	if _, err = s.Get("my-project"); !errors.Is(err, store.ErrTemplateMissing) {
+		t.Fatalf("expected %v, got %v", store.ErrTemplateMissing, err)
+	}
+
+	if err = s.Delete("my-project"); !errors.Is(err, store.ErrTemplateMissing) {
+		t.Fatalf("expected %v, got %v", store.ErrTemplateMissing, err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, store.NewMemoryStore())
+}
+
+func TestDiskStore(t *testing.T) {
+	testStore(t, store.NewDiskStore(t.TempDir()))
+}
+
+func TestInvalidName(t *testing.T) {
+	var s = store.NewMemoryStore()
+	for _, name := range []string{"", ".", "..", "a/b", `a\b`} {
+		if _, err := s.Get(name); !errors.Is(err, store.ErrTemplateName) {
+			t.Errorf("expected %v for %q, got %v", store.ErrTemplateName, name, err)
+		}
+	}
+}
+
+func TestOpen(t *testing.T) {
+	var s, err = store.Open("", t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := s.(*store.DiskStore); !ok {
+		t.Errorf("expected default backend to be a disk store, got %T", s)
+	}
+
+	s, err = store.Open(store.BackendMemory, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := s.(*store.MemoryStore); !ok {
+		t.Errorf("expected memory store, got %T", s)
+	}
+
+	if _, err = store.Open("unknown", ""); !errors.Is(err, store.ErrBackendMissing) {
+		t.Errorf("expected %v, got %v", store.ErrBackendMissing, err)
+	}
+}
@@ -0,0 +1,66 @@
+package quickgo
+
+import (
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo/config"
+	"github.com/pkg/errors"
+)
+
+// Version returns the version of the running QuickGo binary.
+// It returns an empty string for development builds, which have no version.
+func Version() string {
+	var info, ok = debug.ReadBuildInfo()
+	if !ok || info.Main.Version == "" || info.Main.Version == "(devel)" {
+		return ""
+	}
+	return info.Main.Version
+}
+
+// CheckMinVersion returns an error if the running QuickGo version is older than the project's minVersion.
+// Development builds are always allowed to use a project.
+func CheckMinVersion(proj *config.Project) error {
+	var version = Version()
+	if proj.MinVersion == "" || version == "" {
+		return nil
+	}
+
+	if CompareVersions(version, proj.MinVersion) < 0 {
+		return errors.Errorf(
+			"project %s requires QuickGo %s or newer, this is %s",
+			proj.Name, proj.MinVersion, version,
+		)
+	}
+
+	return nil
+}
+
+// CompareVersions compares two semantic versions (e.g. v1.2.3) and returns -1, 0 or 1.
+// The "v" prefix is optional, pre-release and build suffixes are ignored.
+func CompareVersions(a, b string) int {
+	var pa, pb = parseVersion(a), parseVersion(b)
+	for i := range pa {
+		switch {
+		case pa[i] < pb[i]:
+			return -1
+		case pa[i] > pb[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+func parseVersion(v string) [3]int {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+
+	var parts [3]int
+	for i, s := range strings.SplitN(v, ".", 3) {
+		parts[i], _ = strconv.Atoi(s)
+	}
+	return parts
+}
@@ -0,0 +1,26 @@
+package quickgo_test
+
+import (
+	"testing"
+
+	"github.com/Nigel2392/quickgo/v2/quickgo"
+)
+
+func TestCompareVersions(t *testing.T) {
+	var tests = []struct {
+		a, b     string
+		expected int
+	}{
+		{"v1.2.3", "1.2.3", 0},
+		{"v2.0.0", "v1.9.9", 1},
+		{"v1.2", "v1.2.1", -1},
+		{"v2.1.0-20240101000000-abcdef+dirty", "v2.1.0", 0},
+		{"v1.10.0", "v1.9.0", 1},
+	}
+
+	for _, test := range tests {
+		if got := quickgo.CompareVersions(test.a, test.b); got != test.expected {
+			t.Errorf("CompareVersions(%q, %q) = %d, expected %d", test.a, test.b, got, test.expected)
+		}
+	}
+}